@@ -0,0 +1,316 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cron provides the parser of the cron expressions.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	expr string
+
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// Whether the day of month or week is restricted, that's, not "*".
+	domRestricted bool
+	dowRestricted bool
+
+	// Whether neither the minute nor the hour starts with "*",
+	// which runs at the fixed time of the day.
+	fixedTime bool
+}
+
+// Parse parses the standard cron expression with 5 fields separated by spaces:
+//
+//	minute        0-59
+//	hour          0-23
+//	day of month  1-31
+//	month         1-12 or JAN-DEC
+//	day of week   0-7 or SUN-SAT, and both 0 and 7 are Sunday
+//
+// Each field supports "*", the list "a,b", the range "a-b" and the step
+// "*/n" or "a-b/n". The names of the month and the day of week are
+// case-insensitive.
+//
+// If both the day of month and the day of week are restricted,
+// the time matching either of them is matched, like the standard cron.
+//
+// It also supports the descriptors as follow:
+//
+//	@yearly, @annually  "0 0 1 1 *"
+//	@monthly            "0 0 1 * *"
+//	@weekly             "0 0 * * 0"
+//	@daily, @midnight   "0 0 * * *"
+//	@hourly             "0 * * * *"
+func Parse(expr string) (s *Schedule, err error) {
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if spec, ok = descriptors[strings.ToLower(spec)]; !ok {
+			return nil, fmt.Errorf("cron: unknown descriptor '%s'", expr)
+		}
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron: expected 5 fields, but got %d in '%s'", len(fields), expr)
+	}
+
+	s = &Schedule{expr: expr}
+	if s.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if s.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if s.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if s.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+
+	// Sunday is both 0 and 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	s.domRestricted = fields[2] != "*"
+	s.dowRestricted = fields[4] != "*"
+	s.fixedTime = !strings.HasPrefix(fields[0], "*") && !strings.HasPrefix(fields[1], "*")
+	return
+}
+
+// MustParse is the same as Parse, but panics if there is an error.
+func MustParse(expr string) *Schedule {
+	s, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (f field) parse(expr string) (set uint64, err error) {
+	for _, item := range strings.Split(expr, ",") {
+		var _set uint64
+		if _set, err = f.parseItem(item); err != nil {
+			return
+		}
+		set |= _set
+	}
+	return
+}
+
+func (f field) parseItem(item string) (set uint64, err error) {
+	rangeexpr, stepexpr, hasstep := strings.Cut(item, "/")
+
+	step := 1
+	if hasstep {
+		step, err = strconv.Atoi(stepexpr)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("cron: invalid step '%s' of %s", stepexpr, f.name)
+		}
+	}
+
+	var start, end int
+	switch {
+	case rangeexpr == "*":
+		start, end = f.min, f.max
+
+	case strings.Contains(rangeexpr, "-"):
+		first, last, _ := strings.Cut(rangeexpr, "-")
+		if start, err = f.parseValue(first); err != nil {
+			return
+		}
+		if end, err = f.parseValue(last); err != nil {
+			return
+		}
+		if start > end {
+			return 0, fmt.Errorf("cron: invalid range '%s' of %s", rangeexpr, f.name)
+		}
+
+	default:
+		if start, err = f.parseValue(rangeexpr); err != nil {
+			return
+		}
+
+		// "a/n" is equal to "a-max/n".
+		end = start
+		if hasstep {
+			end = f.max
+		}
+	}
+
+	for i := start; i <= end; i += step {
+		set |= 1 << uint(i)
+	}
+	return
+}
+
+func (f field) parseValue(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron: invalid value '%s' of %s, which must be in [%d, %d]",
+			s, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the original cron expression.
+func (s *Schedule) String() string { return s.expr }
+
+func has(set uint64, v int) bool { return set&(1<<uint(v)) != 0 }
+
+func (s *Schedule) matchDay(t time.Time) bool {
+	dom := has(s.dom, t.Day())
+	dow := has(s.dow, int(t.Weekday()))
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Next returns the next time after t matching the schedule,
+// which is in the location of t.
+//
+// Like Vixie cron, when the clock is changed by the daylight saving time,
+// the schedule running at the fixed time, whose minute and hour don't start
+// with "*", runs once at the transition if its time is skipped, and does not
+// run again in the repeated time. The others just follow the wall clock.
+//
+// Return the zero time if no time matches within 5 years,
+// such as "0 0 30 2 *".
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + 5
+
+	for t.Year() <= limit {
+		year, month, day := t.Date()
+		hour, minute := t.Hour(), t.Minute()
+
+		var next time.Time
+		switch {
+		case !has(s.month, int(month)):
+			next = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+
+		case !s.matchDay(t):
+			next = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+
+		// Step the hour and minute by the absolute time instead of the wall
+		// clock, which may be skipped or repeated by the daylight saving time.
+		case !has(s.hour, hour):
+			next = t.Add(time.Hour - time.Duration(minute)*time.Minute)
+
+		case !has(s.minute, minute):
+			next = t.Add(time.Minute)
+
+		case s.fixedTime && isRepeated(t):
+			next = t.Add(time.Minute)
+
+		default:
+			return t
+		}
+
+		if s.fixedTime && s.matchSkipped(t, next) {
+			return next
+		}
+		t = next
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) match(t time.Time) bool {
+	return has(s.month, int(t.Month())) && s.matchDay(t) &&
+		has(s.hour, t.Hour()) && has(s.minute, t.Minute())
+}
+
+// wallClock returns the wall clock time of t in UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+// matchSkipped reports whether the wall clock time skipped
+// by the clock moving forward between from and to matches.
+func (s *Schedule) matchSkipped(from, to time.Time) bool {
+	end := wallClock(to)
+	skipped := end.Sub(wallClock(from)) - to.Sub(from)
+	for t := end.Add(-skipped); t.Before(end); t = t.Add(time.Minute) {
+		if s.match(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRepeated reports whether the wall clock time of t has occurred
+// before t by the clock moving backward.
+func isRepeated(t time.Time) bool {
+	_, offset := t.Zone()
+	_, before := t.Add(-3 * time.Hour).Zone()
+	if before <= offset {
+		return false
+	}
+
+	first := t.Add(-time.Duration(before-offset) * time.Second)
+	return wallClock(first).Equal(wallClock(t))
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseError(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@every",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("expect an error for '%s', but got nil", expr)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 30, 15, 0, time.UTC) // Thursday
+
+	tests := []struct {
+		expr string
+		next string
+	}{
+		{"* * * * *", "2026-01-01 10:31"},
+		{"0 * * * *", "2026-01-01 11:00"},
+		{"@hourly", "2026-01-01 11:00"},
+		{"0 2 * * *", "2026-01-02 02:00"},
+		{"@daily", "2026-01-02 00:00"},
+		{"*/15 * * * *", "2026-01-01 10:45"},
+		{"5/20 * * * *", "2026-01-01 10:45"},
+		{"0 9-17/4 * * *", "2026-01-01 13:00"},
+		{"0,45 10 * * *", "2026-01-01 10:45"},
+		{"0 0 * * mon", "2026-01-05 00:00"},
+		{"0 0 * * 7", "2026-01-04 00:00"},
+		{"0 0 * * SUN", "2026-01-04 00:00"},
+		{"0 0 1 feb *", "2026-02-01 00:00"},
+		{"0 0 29 2 *", "2028-02-29 00:00"},
+		{"0 0 15 * fri", "2026-01-02 00:00"},
+		{"@yearly", "2027-01-01 00:00"},
+	}
+
+	for _, test := range tests {
+		next := MustParse(test.expr).Next(start).Format("2006-01-02 15:04")
+		if next != test.next {
+			t.Errorf("%s: expect next '%s', but got '%s'", test.expr, test.next, next)
+		}
+	}
+
+	if next := MustParse("0 0 30 2 *").Next(start); !next.IsZero() {
+		t.Errorf("expect the zero time, but got '%s'", next)
+	}
+}
+
+func TestScheduleNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("fail to load the location: %s", err)
+	}
+
+	const layout = "2006-01-02 15:04 MST"
+	tests := []struct {
+		expr  string
+		start time.Time
+		next  string
+	}{
+		// 02:00-03:00 is skipped on 2026-03-08, and the fixed time
+		// in it runs once at the transition.
+		{"0 2 * * *", time.Date(2026, 3, 8, 1, 30, 0, 0, loc), "2026-03-08 03:00 EDT"},
+		{"30 2 * * *", time.Date(2026, 3, 8, 1, 30, 0, 0, loc), "2026-03-08 03:00 EDT"},
+		{"30 2 * * *", time.Date(2026, 3, 7, 12, 0, 0, 0, loc), "2026-03-08 03:00 EDT"},
+		{"30 2 * * *", time.Date(2026, 3, 8, 3, 0, 0, 0, loc), "2026-03-09 02:30 EDT"},
+		{"30 3 * * *", time.Date(2026, 3, 8, 1, 30, 0, 0, loc), "2026-03-08 03:30 EDT"},
+		{"30 * * * *", time.Date(2026, 3, 8, 1, 45, 0, 0, loc), "2026-03-08 03:30 EDT"},
+		{"*/20 * * * *", time.Date(2026, 3, 8, 1, 50, 0, 0, loc), "2026-03-08 03:00 EDT"},
+		{"30 2-3 * * *", time.Date(2026, 3, 8, 1, 30, 0, 0, loc), "2026-03-08 03:00 EDT"},
+
+		// 01:00-02:00 is repeated on 2026-11-01, and the fixed time
+		// in it does not run again.
+		{"30 1 * * *", time.Date(2026, 11, 1, 0, 0, 0, 0, loc), "2026-11-01 01:30 EDT"},
+		{"30 1 * * *", time.Date(2026, 11, 1, 1, 30, 0, 0, loc), "2026-11-02 01:30 EST"},
+		{"45 1 * * *", time.Date(2026, 11, 1, 1, 30, 0, 0, loc), "2026-11-01 01:45 EDT"},
+		{"45 1 * * *", time.Date(2026, 11, 1, 1, 30, 0, 0, loc).Add(time.Hour), "2026-11-02 01:45 EST"},
+		{"0 2 * * *", time.Date(2026, 11, 1, 1, 30, 0, 0, loc), "2026-11-01 02:00 EST"},
+		{"0 * * * *", time.Date(2026, 11, 1, 1, 30, 0, 0, loc), "2026-11-01 01:00 EST"},
+		{"*/30 1 * * *", time.Date(2026, 11, 1, 1, 45, 0, 0, loc), "2026-11-01 01:00 EST"},
+	}
+
+	for _, test := range tests {
+		next := MustParse(test.expr).Next(test.start)
+		if !next.After(test.start) {
+			t.Errorf("%s: expect the next time after '%s', but got '%s'",
+				test.expr, test.start.Format(layout), next.Format(layout))
+		} else if s := next.Format(layout); s != test.next {
+			t.Errorf("%s: expect next '%s', but got '%s'", test.expr, test.next, s)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"encoding/json"
+	"expvar"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/xgfone/gconf/v6"
+	"github.com/xgfone/goapp/log"
+)
+
+// AdminMux is the http handler of the admin server, which is disabled
+// by default and enabled by the option "admin.addr".
+//
+// It has registered the routes as follow:
+//
+//	GET /debug/vars
+//	GET /debug/pprof/
+//	GET /debug/pprof/{name}
+//	GET /debug/pprof/cmdline
+//	GET /debug/pprof/profile
+//	GET /debug/pprof/trace
+//	GET /debug/config
+//	GET /debug/loglevel
+//	PUT /debug/loglevel
+//	GET /healthz
+//	GET /readyz
+//
+// The app may register other routes into it.
+var AdminMux = http.NewServeMux()
+
+func init() {
+	AdminMux.Handle("GET /debug/vars", expvar.Handler())
+	AdminMux.HandleFunc("GET /debug/pprof/{$}", pprofIndex)
+	AdminMux.HandleFunc("GET /debug/pprof/{name}", pprofProfile)
+	AdminMux.HandleFunc("GET /debug/pprof/cmdline", pprofCmdline)
+	AdminMux.HandleFunc("GET /debug/pprof/profile", pprofCPU)
+	AdminMux.HandleFunc("GET /debug/pprof/trace", pprofTrace)
+	AdminMux.HandleFunc("GET /debug/config", getConfig)
+	AdminMux.HandleFunc("GET /debug/loglevel", getLogLevel)
+	AdminMux.HandleFunc("PUT /debug/loglevel", setLogLevel)
+	AdminMux.HandleFunc("GET /healthz", healthz)
+	AdminMux.HandleFunc("GET /readyz", readyz)
+
+	RegisterHTTPServer("admin", "", AdminMux)
+}
+
+func sendJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("fail to encode the response", "err", err)
+	}
+}
+
+func sendError(w http.ResponseWriter, code int, err error) {
+	sendJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+// redactedWords is the words of the option names whose values are secret.
+var redactedWords = []string{"password", "passwd", "secret", "token", "key"}
+
+// redactConfig returns the current values of all the options,
+// which are redacted if they may be secret.
+func redactConfig() map[string]any {
+	opts := gconf.GetAllOpts()
+	configs := make(map[string]any, len(opts))
+	for _, opt := range opts {
+		value := gconf.Get(opt.Name)
+		if duration, ok := value.(time.Duration); ok {
+			value = duration.String()
+		}
+
+		lower := strings.ToLower(opt.Name)
+		for _, word := range redactedWords {
+			if strings.Contains(lower, word) {
+				value = "******"
+				break
+			}
+		}
+		configs[opt.Name] = value
+	}
+	return configs
+}
+
+func getConfig(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, 200, redactConfig())
+}
+
+func getLogLevel(w http.ResponseWriter, r *http.Request) {
+	sendJSON(w, 200, map[string]string{"level": log.Level.Level().String()})
+}
+
+// setLogLevel resets the log level by the query argument "level",
+// or the request body if the query argument is missing.
+func setLogLevel(w http.ResponseWriter, r *http.Request) {
+	level := r.URL.Query().Get("level")
+	if level == "" {
+		data, err := io.ReadAll(io.LimitReader(r.Body, 64))
+		if err != nil {
+			sendError(w, 400, err)
+			return
+		}
+		level = strings.TrimSpace(string(data))
+	}
+
+	if err := log.SetLevel(level); err != nil {
+		sendError(w, 400, err)
+		return
+	}
+
+	slog.Info("reset the log level by the admin server", "level", level)
+	getLogLevel(w, r)
+}
@@ -19,27 +19,81 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/xgfone/gconf/v6"
 	"github.com/xgfone/go-toolkit/app"
 	"github.com/xgfone/go-toolkit/runtimex"
+	"github.com/xgfone/goapp/log"
 )
 
+var shutdowntimeout = gconf.DurationOpt("app.shutdowntimeout", "The maximum duration to stop the app gracefully.").
+	D(time.Second * 10)
+
+func init() {
+	gconf.RegisterOpts(shutdowntimeout)
+}
+
+// ShutdownTimeout returns the maximum duration to stop the app gracefully,
+// which is configured by the option "app.shutdowntimeout".
+func ShutdownTimeout() time.Duration {
+	if timeout := gconf.GetDuration(shutdowntimeout.Name); timeout > 0 {
+		return timeout
+	}
+	return time.Second
+}
+
+// stopctx is used to stop the app actively, such as the server failure.
+var stopctx, stopApp = context.WithCancel(context.Background())
+
+// Run runs the default app, and calls the stop hooks registered by OnStop
+// before stopping it when the exit signal is received.
+//
+// If the command line arguments start with the name of a command
+// registered by RegisterCommand, run the command instead of the app.
 func Run() {
-	err := app.Run(runtimex.ExitContext())
+	if runCommand() {
+		return
+	}
+
+	exitctx := runtimex.ExitContext()
+	ctx, cancel := context.WithCancel(context.WithoutCancel(exitctx))
+	defer cancel()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-exitctx.Done():
+		case <-stopctx.Done():
+		}
+
+		shutdown()
+		cancel()
+	}()
+
+	err := app.Run(ctx)
 	if err != nil {
 		slog.Error("fail to run app", "err", err)
 	}
+
+	shutdown()
 }
 
 func init() {
 	exit := runtimex.GetExitFunc()
 	runtimex.SetExitFunc(func(code int) {
+		timeout := ShutdownTimeout()
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		runStopHooks(ctx)
 		app.DefaultApp.Stop()
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-		defer cancel()
+		if err := app.DefaultApp.WaitContext(ctx); err != nil {
+			slog.Error("the app fails to stop in time", "timeout", timeout, "err", err)
+		}
 
-		_ = app.DefaultApp.WaitContext(ctx)
-		time.Sleep(time.Millisecond * 50)
+		// Flush the buffered logs instead of waiting for them.
+		_ = log.Flush()
 
 		exit(code)
 	})
@@ -0,0 +1,236 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/xgfone/gconf/v6"
+	"github.com/xgfone/go-toolkit/app"
+	"github.com/xgfone/goapp/log"
+)
+
+// Command is a subcommand of the app, which is run instead of the app
+// when the command line arguments start with its name.
+type Command struct {
+	// Name is the name of the command, which may contain the space
+	// to be a nested command, such as "config dump".
+	Name string
+
+	// Help is the short description of the command.
+	Help string
+
+	// Run runs the command with the remaining non-flag arguments
+	// after loading the configs.
+	Run func(ctx context.Context, args []string) error
+}
+
+var (
+	commandlock sync.RWMutex
+	commands    = make(map[string]Command)
+)
+
+func init() {
+	RegisterCommand(Command{Name: "version", Help: "Print the version and build information.", Run: runVersionCommand})
+	RegisterCommand(Command{Name: "config dump", Help: "Print the current configs with the secrets redacted.", Run: runConfigDump})
+	RegisterCommand(Command{Name: "config check", Help: "Check whether the configs are valid.", Run: runConfigCheck})
+	RegisterCommand(Command{Name: "config example", Help: "Print an example config file in the ini format.", Run: runConfigExample})
+}
+
+// RegisterCommand registers a subcommand, such as "migrate",
+// which will override the existed command with the same name.
+//
+// When running the app by Run, if the command line arguments start with
+// the name of a command, such as "app migrate --opt1 value1 arg1 arg2",
+// the command is run with the configs loaded by the same way as the app,
+// then the program exits without starting the app.
+func RegisterCommand(cmd Command) {
+	cmd.Name = strings.Join(strings.Fields(cmd.Name), " ")
+	if cmd.Name == "" {
+		panic("RegisterCommand: the command name must not be empty")
+	}
+	if cmd.Run == nil {
+		panic("RegisterCommand: the command function must not be nil")
+	}
+
+	commandlock.Lock()
+	defer commandlock.Unlock()
+	commands[cmd.Name] = cmd
+}
+
+// lookupCommand returns the longest command matching the prefix of args,
+// and the number of the words of the command name.
+func lookupCommand(args []string) (cmd Command, n int, ok bool) {
+	commandlock.RLock()
+	defer commandlock.RUnlock()
+
+	for n = len(args); n > 0; n-- {
+		if cmd, ok = commands[strings.Join(args[:n], " ")]; ok {
+			return
+		}
+	}
+	return
+}
+
+// runCommand runs the command specified by the command line arguments,
+// and exits the program after it finishes.
+//
+// Return false if no command is specified.
+func runCommand() bool {
+	end := slices.IndexFunc(os.Args[1:], func(arg string) bool { return strings.HasPrefix(arg, "-") })
+	if end < 0 {
+		end = len(os.Args) - 1
+	}
+
+	cmd, n, ok := lookupCommand(os.Args[1 : 1+end])
+	if !ok {
+		return false
+	}
+
+	// Remove the command name to parse the flags.
+	os.Args = append(os.Args[:1], os.Args[1+n:]...)
+
+	ctx := context.Background()
+	err := loadConfig(ctx, app.DefaultApp)
+	if err == nil {
+		err = cmd.Run(ctx, flag.Args())
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", cmd.Name, err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+	return true
+}
+
+func runVersionCommand(context.Context, []string) error {
+	return printVersion(*versionfmt)
+}
+
+func runConfigDump(context.Context, []string) error {
+	data, err := json.MarshalIndent(redactConfig(), "", "  ")
+	if err == nil {
+		fmt.Println(string(data))
+	}
+	return err
+}
+
+func runConfigCheck(context.Context, []string) error {
+	var errs []error
+	for _, opt := range gconf.GetAllOpts() {
+		value := gconf.Get(opt.Name)
+		for _, validate := range opt.Validators {
+			if err := validate(value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid option '%s': %w", opt.Name, err))
+				break
+			}
+		}
+	}
+
+	if _, err := log.ParseLevel(gconf.GetString(loglevel.Name)); err != nil {
+		errs = append(errs, fmt.Errorf("invalid option '%s': %w", loglevel.Name, err))
+	}
+
+	if config, err := getLogFileConfig(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid log file options: %w", err))
+	} else if err = log.CheckFileConfig(config); err != nil {
+		errs = append(errs, fmt.Errorf("invalid log file options: %w", err))
+	}
+
+	httplock.Lock()
+	for _, s := range httpservers {
+		if _, _, err := s.tlsFiles(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid options of the http server '%s': %w", s.name, err))
+		}
+	}
+	httplock.Unlock()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	fmt.Println("the configs are valid")
+	return nil
+}
+
+func runConfigExample(context.Context, []string) error {
+	opts := gconf.GetAllOpts()
+	slices.SortFunc(opts, func(a, b gconf.Opt) int {
+		agroup, _ := splitOptName(a.Name)
+		bgroup, _ := splitOptName(b.Name)
+		if c := strings.Compare(agroup, bgroup); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var b strings.Builder
+	lastgroup := ""
+	for _, opt := range opts {
+		if opt.Name == gconf.ConfigFileOpt.Name {
+			continue
+		}
+
+		group, name := splitOptName(opt.Name)
+		if group != lastgroup {
+			fmt.Fprintf(&b, "\n[%s]\n", group)
+			lastgroup = group
+		}
+
+		if opt.Help != "" {
+			fmt.Fprintf(&b, "# %s\n", opt.Help)
+		}
+		fmt.Fprintf(&b, "%s = %s\n\n", name, formatOptDefault(opt.Default))
+	}
+
+	fmt.Print(strings.TrimLeft(b.String(), "\n"))
+	return nil
+}
+
+func splitOptName(name string) (group, short string) {
+	if index := strings.LastIndexByte(name, '.'); index > -1 {
+		return name[:index], name[index+1:]
+	}
+	return "", name
+}
+
+func formatOptDefault(value any) string {
+	if value == nil {
+		return ""
+	}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Slice, reflect.Array:
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(values, ",")
+
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -16,6 +16,10 @@ package goapp
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
 
 	"github.com/xgfone/gconf/v6"
 	"github.com/xgfone/go-toolkit/app"
@@ -25,10 +29,24 @@ func init() {
 	app.DefaultApp.SetConfigLoader(loadConfig)
 }
 
+var (
+	versionflags sync.Once
+	versionlong  *bool
+	versionshort *bool
+	versionfmt   *string
+)
+
+func addVersionFlags() {
+	versionlong = flag.Bool("version", false, "Print the version and build information, then exit.")
+	versionshort = flag.Bool("V", false, "Print the version and build information, then exit.")
+	versionfmt = flag.String("version-format", "text", "The format of the version information, text or json.")
+}
+
 func loadConfig(ctx context.Context, app *app.App) (err error) {
-	if version := app.Version(); version != "" {
-		gconf.SetVersion(version)
-	}
+	// Disable the version flag of gconf, which only prints the version,
+	// to be replaced by the flags printing the build information.
+	gconf.Conf.Version.Default = nil
+	versionflags.Do(addVersionFlags)
 
 	// Register and Parse the options with flag
 	err = gconf.AddAndParseOptFlag(gconf.Conf)
@@ -36,6 +54,14 @@ func loadConfig(ctx context.Context, app *app.App) (err error) {
 		return
 	}
 
+	if *versionlong || *versionshort {
+		if err := printVersion(*versionfmt); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Load the configs from flag
 	err = gconf.LoadSource(gconf.NewFlagSource())
 	if err != nil {
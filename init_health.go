@@ -0,0 +1,219 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/go-toolkit/app"
+)
+
+// HealthCheckKind is the kind of the health check.
+type HealthCheckKind int
+
+// Predefine some kinds of the health checks.
+const (
+	// HealthCheckReadiness is only run by "/readyz", such as the checks
+	// of the dependencies, whose failure should stop the traffic,
+	// but not restart the app.
+	HealthCheckReadiness HealthCheckKind = iota
+
+	// HealthCheckLiveness is run by both "/healthz" and "/readyz",
+	// such as the checks of the app itself, whose failure means
+	// that the app should be restarted.
+	HealthCheckLiveness
+)
+
+// HealthCheck is a named health check of a component.
+type HealthCheck struct {
+	Name  string
+	Check func(context.Context) error
+
+	// Kind is the kind of the check.
+	//
+	// Default: HealthCheckReadiness
+	Kind HealthCheckKind
+
+	// Timeout is the maximum duration to run the check.
+	//
+	// Default: 3s
+	Timeout time.Duration
+
+	// If true, the failure of the check makes the app unhealthy.
+	// Or, it is only reported.
+	Critical bool
+}
+
+// HealthResult is the result of a health check.
+type HealthResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Latency  string `json:"latency"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Health is the health status of the app.
+type Health struct {
+	Status string         `json:"status"`
+	Checks []HealthResult `json:"checks,omitempty"`
+}
+
+// Predefine some health statuses.
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusFail    = "fail"
+	HealthStatusUnready = "unready"
+)
+
+var (
+	ready        atomic.Bool
+	healthlock   sync.RWMutex
+	healthchecks []HealthCheck
+)
+
+func init() {
+	app.StageReady.On(func(context.Context, *app.App) error {
+		ready.Store(true)
+		return nil
+	})
+
+	OnStop("ready", StopPriorityFirst, func(context.Context) error {
+		ready.Store(false)
+		return nil
+	})
+}
+
+// IsReady reports whether the app is ready to serve, which becomes true
+// after the app is ready and false when the app starts to stop.
+func IsReady() bool { return ready.Load() }
+
+// RegisterHealthCheck registers a health check, which is run
+// by the health endpoints "/healthz" and "/readyz" of the admin server
+// according to its kind.
+func RegisterHealthCheck(check HealthCheck) {
+	if check.Name == "" {
+		panic("RegisterHealthCheck: the health check name must not be empty")
+	}
+	if check.Check == nil {
+		panic("RegisterHealthCheck: the health check function must not be nil")
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = time.Second * 3
+	}
+
+	healthlock.Lock()
+	defer healthlock.Unlock()
+	healthchecks = append(healthchecks, check)
+}
+
+// CheckHealth runs all the registered health checks concurrently,
+// and returns the health status of the app for the readiness.
+//
+// The status is HealthStatusFail if any critical check fails.
+func CheckHealth(ctx context.Context) (health Health) {
+	return checkHealth(ctx, false)
+}
+
+// CheckLiveness is the same as CheckHealth, but only runs the health checks
+// with the kind HealthCheckLiveness.
+func CheckLiveness(ctx context.Context) (health Health) {
+	return checkHealth(ctx, true)
+}
+
+func checkHealth(ctx context.Context, liveness bool) (health Health) {
+	healthlock.RLock()
+	checks := healthchecks
+	healthlock.RUnlock()
+
+	if liveness {
+		checks = slices.DeleteFunc(slices.Clone(checks), func(check HealthCheck) bool {
+			return check.Kind != HealthCheckLiveness
+		})
+	}
+
+	var wg sync.WaitGroup
+	health.Checks = make([]HealthResult, len(checks))
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			health.Checks[i] = runHealthCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	health.Status = HealthStatusOK
+	for _, result := range health.Checks {
+		if result.Critical && result.Status != HealthStatusOK {
+			health.Status = HealthStatusFail
+			break
+		}
+	}
+	return
+}
+
+func runHealthCheck(ctx context.Context, check HealthCheck) HealthResult {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	errch := make(chan error, 1)
+	go func() { errch <- check.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-errch:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := HealthResult{
+		Name:     check.Name,
+		Status:   HealthStatusOK,
+		Critical: check.Critical,
+		Latency:  time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = HealthStatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func sendHealth(w http.ResponseWriter, health Health) {
+	if health.Status == HealthStatusOK {
+		sendJSON(w, 200, health)
+	} else {
+		sendJSON(w, 503, health)
+	}
+}
+
+func healthz(w http.ResponseWriter, r *http.Request) {
+	sendHealth(w, CheckLiveness(r.Context()))
+}
+
+func readyz(w http.ResponseWriter, r *http.Request) {
+	if IsReady() {
+		sendHealth(w, CheckHealth(r.Context()))
+	} else {
+		sendHealth(w, Health{Status: HealthStatusUnready})
+	}
+}
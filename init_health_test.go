@@ -0,0 +1,142 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// setHealthChecks replaces the registered health checks during the test.
+func setHealthChecks(t *testing.T, checks ...HealthCheck) {
+	healthlock.Lock()
+	origin := healthchecks
+	healthchecks = nil
+	healthlock.Unlock()
+
+	t.Cleanup(func() {
+		healthlock.Lock()
+		healthchecks = origin
+		healthlock.Unlock()
+	})
+
+	for _, check := range checks {
+		RegisterHealthCheck(check)
+	}
+}
+
+func getHealth(t *testing.T, handler http.HandlerFunc) (code int, health Health) {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), &health); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, health
+}
+
+func TestHealthzOnlyLiveness(t *testing.T) {
+	fail := func(context.Context) error { return errors.New("test") }
+	setHealthChecks(t,
+		HealthCheck{Name: "app", Kind: HealthCheckLiveness, Critical: true, Check: func(context.Context) error { return nil }},
+		HealthCheck{Name: "db", Critical: true, Check: fail},
+	)
+
+	// The failure of the dependency does not fail the liveness.
+	if code, health := getHealth(t, healthz); code != 200 || len(health.Checks) != 1 || health.Checks[0].Name != "app" {
+		t.Errorf("expect only the liveness check to pass, but got %d %+v", code, health)
+	}
+
+	health := CheckHealth(context.Background())
+	if health.Status != HealthStatusFail || len(health.Checks) != 2 {
+		t.Errorf("expect the readiness to fail by the dependency, but got %+v", health)
+	}
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	setHealthChecks(t,
+		HealthCheck{
+			Name:     "slow",
+			Critical: true,
+			Timeout:  time.Millisecond * 20,
+			Check:    func(ctx context.Context) error { <-ctx.Done(); time.Sleep(time.Second); return nil },
+		},
+		HealthCheck{
+			Name:    "optional",
+			Timeout: time.Millisecond * 20,
+			Check:   func(context.Context) error { time.Sleep(time.Second); return nil },
+		},
+	)
+
+	start := time.Now()
+	health := CheckHealth(context.Background())
+	if cost := time.Since(start); cost >= time.Second {
+		t.Errorf("expect the checks to time out, but cost %s", cost)
+	}
+
+	if health.Status != HealthStatusFail {
+		t.Errorf("expect the status '%s', but got '%s'", HealthStatusFail, health.Status)
+	}
+	for _, result := range health.Checks {
+		if result.Status != HealthStatusFail || result.Error != context.DeadlineExceeded.Error() {
+			t.Errorf("%s: expect the check to time out, but got %+v", result.Name, result)
+		}
+	}
+
+	// The non-critical check is only reported.
+	setHealthChecks(t, HealthCheck{
+		Name:    "optional",
+		Timeout: time.Millisecond * 20,
+		Check:   func(context.Context) error { time.Sleep(time.Second); return nil },
+	})
+	if health := CheckHealth(context.Background()); health.Status != HealthStatusOK {
+		t.Errorf("expect the status '%s', but got '%s'", HealthStatusOK, health.Status)
+	}
+}
+
+func TestReadyzFlip(t *testing.T) {
+	setHealthChecks(t)
+	defer ready.Store(false)
+
+	if code, health := getHealth(t, readyz); code != 503 || health.Status != HealthStatusUnready {
+		t.Errorf("expect unready before the app is ready, but got %d %+v", code, health)
+	}
+
+	ready.Store(true) // Set by the StageReady handler.
+	if code, health := getHealth(t, readyz); code != 200 || health.Status != HealthStatusOK {
+		t.Errorf("expect ready after the app is ready, but got %d %+v", code, health)
+	}
+
+	// Run the registered stop hook marking the app unready.
+	stoplock.Lock()
+	hooks := stophooks
+	stoplock.Unlock()
+	for _, hook := range hooks {
+		if hook.name == "ready" {
+			if hook.priority != StopPriorityFirst {
+				t.Errorf("expect the first priority, but got %d", hook.priority)
+			}
+			hook.stop(context.Background())
+		}
+	}
+
+	if code, health := getHealth(t, readyz); code != 503 || health.Status != HealthStatusUnready {
+		t.Errorf("expect unready after the app starts to stop, but got %d %+v", code, health)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xgfone/gconf/v6"
+	"github.com/xgfone/go-toolkit/app"
+)
+
+type httpserver struct {
+	name    string
+	group   *gconf.OptGroup
+	handler http.Handler
+}
+
+var (
+	httplock    sync.Mutex
+	httpservers []httpserver
+)
+
+// RegisterHTTPServer registers a managed http server named name with the handler,
+// and registers its options into the group named name as follow:
+//
+//	addr:         The address to listen on. If empty, the server is disabled.
+//	certfile:     The path of the TLS certificate file to enable HTTPS.
+//	keyfile:      The path of the TLS private key file to enable HTTPS.
+//	readtimeout:  The maximum duration for reading the entire request.
+//	writetimeout: The maximum duration before timing out writes of the response.
+//	idletimeout:  The maximum duration to wait for the next request.
+//
+// The server is started after the app is ready, and the failure to listen
+// fails the app. When stopping the app, it is shut down gracefully
+// within the shutdown deadline.
+//
+// It should be called before running the app, such as in init.
+func RegisterHTTPServer(name, defaultAddr string, handler http.Handler) {
+	if handler == nil {
+		panic("RegisterHTTPServer: the http handler must not be nil")
+	}
+
+	group := gconf.Group(name)
+	group.RegisterOpts(
+		gconf.StrOpt("addr", "The address to listen on. If empty, disable it.").D(defaultAddr),
+		gconf.StrOpt("certfile", "The path of the TLS certificate file to enable HTTPS."),
+		gconf.StrOpt("keyfile", "The path of the TLS private key file to enable HTTPS."),
+		gconf.DurationOpt("readtimeout", "The maximum duration for reading the entire request.").D(time.Minute),
+		gconf.DurationOpt("writetimeout", "The maximum duration before timing out writes of the response.").D(time.Minute),
+		gconf.DurationOpt("idletimeout", "The maximum duration to wait for the next request.").D(time.Minute*3),
+	)
+
+	httplock.Lock()
+	defer httplock.Unlock()
+	httpservers = append(httpservers, httpserver{name: name, group: group, handler: handler})
+}
+
+func init() {
+	app.StageReady.On(func(context.Context, *app.App) error {
+		httplock.Lock()
+		servers := httpservers
+		httplock.Unlock()
+
+		for _, s := range servers {
+			if err := s.start(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s httpserver) tlsFiles() (certfile, keyfile string, err error) {
+	certfile = s.group.GetString("certfile")
+	keyfile = s.group.GetString("keyfile")
+	if (certfile == "") != (keyfile == "") {
+		err = errors.New("certfile and keyfile must be set together")
+	}
+	return
+}
+
+func (s httpserver) start() (err error) {
+	addr := s.group.GetString("addr")
+	if addr == "" {
+		return
+	}
+
+	certfile, keyfile, err := s.tlsFiles()
+	if err != nil {
+		return fmt.Errorf("fail to start the http server '%s': %w", s.name, err)
+	}
+
+	ln, err := listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("fail to start the http server '%s': %w", s.name, err)
+	}
+
+	server := &http.Server{
+		Handler:      s.handler,
+		ReadTimeout:  s.group.GetDuration("readtimeout"),
+		WriteTimeout: s.group.GetDuration("writetimeout"),
+		IdleTimeout:  s.group.GetDuration("idletimeout"),
+		ErrorLog:     slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+	}
+
+	OnStop("http:"+s.name, StopPriorityServer, server.Shutdown)
+
+	go func() {
+		slog.Info("start the http server", "name", s.name, "addr", addr, "tls", certfile != "")
+
+		var err error
+		if certfile != "" {
+			err = server.ServeTLS(ln, certfile, keyfile)
+		} else {
+			err = server.Serve(ln)
+		}
+
+		if errors.Is(err, http.ErrServerClosed) {
+			slog.Info("stop the http server", "name", s.name, "addr", addr)
+		} else {
+			slog.Error("the http server fails", "name", s.name, "addr", addr, "err", err)
+			stopApp()
+		}
+	}()
+
+	return
+}
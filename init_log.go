@@ -16,7 +16,11 @@ package goapp
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"os"
+	"strconv"
+	"sync/atomic"
 
 	"github.com/xgfone/gconf/v6"
 	"github.com/xgfone/go-toolkit/app"
@@ -28,9 +32,19 @@ var (
 			As("loglevel").D("info").U(updateLogLevel)
 	logfile0 = gconf.StrOpt("log.file", "The file path of the log. The default is stderr.").
 			As("logfile")
-	logfilenum = gconf.IntOpt("log.filenum", "The number of the log files.").D(100)
+	logfilenum      = gconf.IntOpt("log.filenum", "The number of the log files.").D(100)
+	logfilesync     = gconf.StrOpt("log.filesync", "The fsync policy of the log file, such as never, always, 1M or 1s.").D("never")
+	logfilesize     = gconf.StrOpt("log.filesize", "The maximum size of each log file, such as 100M, 1G.").D("100M")
+	logfilemode     = gconf.StrOpt("log.filemode", "The permission of the log files in octal.").D("0644")
+	logdirmode      = gconf.StrOpt("log.dirmode", "The permission of the log directory in octal.").D("0700")
+	logfilenaming   = gconf.StrOpt("log.filenaming", "The naming mode of the rotated log files, index or timestamp.").D("index")
+	logfilecompress = gconf.BoolOpt("log.filecompress", "Whether to compress the rotated log files by gzip.")
+	logfilekey      = gconf.StrOpt("log.filekey", "The hex-encoded AES key with 16, 24 or 32 bytes to encrypt the log files.")
 )
 
+// logfileinited is used to update the log file only after initialized.
+var logfileinited atomic.Bool
+
 func updateLogLevel(old, new any) {
 	if err := log.SetLevel(new.(string)); err != nil {
 		slog.Error("update the log level", "old", old, "new", new, "err", err)
@@ -39,15 +53,89 @@ func updateLogLevel(old, new any) {
 	}
 }
 
+func updateLogFile(old, new any) {
+	resetLogFile(slog.Any("old", old), slog.Any("new", new))
+}
+
+// updateLogFileKey does not log the values of the secret key.
+func updateLogFileKey(old, new any) { resetLogFile() }
+
+func resetLogFile(attrs ...any) {
+	if !logfileinited.Load() {
+		return
+	}
+
+	config, err := getLogFileConfig()
+	if err == nil {
+		if config.Name == "" {
+			config.Name = "stderr"
+		}
+		err = log.SetFile(config)
+	}
+
+	if err != nil {
+		slog.Error("fail to update the log file", append(attrs, "err", err)...)
+	} else {
+		slog.Info("update the log file", attrs...)
+	}
+}
+
+func getLogFileConfig() (config log.FileConfig, err error) {
+	filemode, err := parseFileMode(gconf.GetString(logfilemode.Name))
+	if err != nil {
+		return
+	}
+
+	dirmode, err := parseFileMode(gconf.GetString(logdirmode.Name))
+	if err != nil {
+		return
+	}
+
+	config = log.FileConfig{
+		Name:     gconf.GetString(logfile0.Name),
+		Num:      gconf.GetInt(logfilenum.Name),
+		Sync:     gconf.GetString(logfilesync.Name),
+		Size:     gconf.GetString(logfilesize.Name),
+		Mode:     filemode,
+		DirMode:  dirmode,
+		Naming:   gconf.GetString(logfilenaming.Name),
+		Compress: gconf.GetBool(logfilecompress.Name),
+		Key:      gconf.GetString(logfilekey.Name),
+	}
+	return
+}
+
+func parseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid file mode '%s'", s)
+	}
+	return os.FileMode(mode), nil
+}
+
 func init() {
-	gconf.RegisterOpts(logfile0, loglevel, logfilenum)
+	fileopts := []gconf.Opt{logfile0, logfilenum, logfilesync, logfilesize,
+		logfilemode, logdirmode, logfilenaming, logfilecompress}
+	for i := range fileopts {
+		fileopts[i] = fileopts[i].U(updateLogFile)
+	}
+	fileopts = append(fileopts, logfilekey.U(updateLogFileKey))
+
+	gconf.RegisterOpts(loglevel)
+	gconf.RegisterOpts(fileopts...)
 }
 
 func init() {
 	app.StageInit.On(func(context.Context, *app.App) error {
-		logfile := gconf.GetString(logfile0.Name)
+		config, err := getLogFileConfig()
+		if err != nil {
+			return err
+		}
+
 		loglevel := gconf.GetString(loglevel.Name)
-		logfilenum := gconf.GetInt(logfilenum.Name)
-		return log.Init(loglevel, logfile, logfilenum)
+		if err = log.InitFile(loglevel, config); err == nil {
+			logfileinited.Store(true)
+		}
+		return err
 	})
 }
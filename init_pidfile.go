@@ -0,0 +1,198 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/xgfone/gconf/v6"
+	"github.com/xgfone/go-toolkit/app"
+)
+
+var (
+	pidfile  = gconf.StrOpt("app.pidfile", "The path of the file to store the process id. If empty, disable it.")
+	lockfile = gconf.StrOpt("app.lockfile", "The path of the file locked exclusively to allow only one instance. If empty, disable it.")
+)
+
+var lockedfile *os.File
+
+func init() {
+	gconf.RegisterOpts(pidfile, lockfile)
+	app.StageInit.On(acquireInstance)
+	app.StageExited.On(releaseInstance)
+}
+
+// acquireInstance acquires the instance lock and writes the pid file.
+func acquireInstance(context.Context, *app.App) (err error) {
+	if path := gconf.GetString(lockfile.Name); path != "" {
+		if lockedfile, err = acquireLockFile(path); err != nil {
+			return
+		}
+		slog.Debug("acquire the instance lock", "file", path)
+	}
+
+	// The new process started by Restart writes the instance files
+	// only after taking over from the parent process, so that they are
+	// not left with the pid of the new process which fails to start.
+	if isRestarted() {
+		return nil
+	}
+	return writeInstanceFiles()
+}
+
+// writeInstanceFiles writes the current process id into the lock file
+// and the pid file.
+func writeInstanceFiles() error {
+	if lockedfile != nil {
+		if err := writeLockFile(lockedfile); err != nil {
+			return err
+		}
+	}
+
+	if path := gconf.GetString(pidfile.Name); path != "" {
+		return writePidFile(path)
+	}
+	return nil
+}
+
+// restoreInstanceFiles rewrites the current process id into the lock file
+// and the pid file after the new process fails to take over them by Restart.
+func restoreInstanceFiles() {
+	if lockedfile != nil {
+		if err := writeLockFile(lockedfile); err != nil {
+			slog.Error("fail to restore the lock file", "err", err)
+		}
+	}
+
+	if path := gconf.GetString(pidfile.Name); path != "" {
+		if err := writePid(path); err != nil {
+			slog.Error("fail to restore the pid file", "file", path, "err", err)
+		}
+	}
+}
+
+// releaseInstance removes the pid file and releases the instance lock.
+func releaseInstance(context.Context, *app.App) error {
+	if path := gconf.GetString(pidfile.Name); path != "" {
+		removePidFile(path)
+	}
+
+	if lockedfile != nil {
+		// Closing the file releases the lock, and the file is kept
+		// to avoid the race that another instance locks the removed file.
+		if err := lockedfile.Close(); err != nil {
+			slog.Error("fail to release the instance lock", "file", lockedfile.Name(), "err", err)
+		}
+		lockedfile = nil
+	}
+	return nil
+}
+
+// acquireLockFile opens and locks the file exclusively.
+//
+// The lock is released automatically by the system when the process exits,
+// so the file left by the crashed process does not block the new instance.
+func acquireLockFile(path string) (file *os.File, err error) {
+	// Reuse the lock inherited from the parent process by Restart,
+	// which shares the lock with the parent.
+	if file = inheritedFile("lock:" + path); file == nil {
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("fail to open the lock file: %w", err)
+		}
+	}
+
+	if err = flock(file); err != nil {
+		pid, _ := readPid(file)
+		file.Close()
+
+		if errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("another instance (pid %d) is running, which holds the lock file '%s'", pid, path)
+		}
+		return nil, fmt.Errorf("fail to lock the file '%s': %w", path, err)
+	}
+
+	return
+}
+
+// writeLockFile writes the current process id into the locked file.
+func writeLockFile(file *os.File) (err error) {
+	if err = file.Truncate(0); err == nil {
+		_, err = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	if err != nil {
+		return fmt.Errorf("fail to write the lock file '%s': %w", file.Name(), err)
+	}
+	return
+}
+
+func readPid(r io.Reader) (pid int, err error) {
+	data, err := io.ReadAll(io.LimitReader(r, 32))
+	if err == nil {
+		pid, err = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+	return
+}
+
+func readPidFile(path string) (pid int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	return readPid(file)
+}
+
+// writePidFile writes the current process id into the pid file.
+//
+// If the pid file exists and its process is still alive, return an error,
+// except the parent process restarting the app. Or, it is regarded
+// as stale and overwritten.
+func writePidFile(path string) error {
+	if pid, err := readPidFile(path); err == nil && pid != os.Getpid() {
+		// The parent process is still draining the connections by Restart.
+		if processExists(pid) && !(isRestarted() && pid == os.Getppid()) {
+			return fmt.Errorf("another instance (pid %d) is running, which writes the pid file '%s'", pid, path)
+		}
+		slog.Warn("overwrite the stale pid file", "file", path, "pid", pid)
+	}
+
+	if err := writePid(path); err != nil {
+		return fmt.Errorf("fail to write the pid file: %w", err)
+	}
+	return nil
+}
+
+func writePid(path string) error {
+	return os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+}
+
+// removePidFile removes the pid file only if it is written by this process.
+func removePidFile(path string) {
+	if pid, err := readPidFile(path); err != nil || pid != os.Getpid() {
+		return
+	}
+
+	if err := os.Remove(path); err != nil {
+		slog.Error("fail to remove the pid file", "file", path, "err", err)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package goapp
+
+import (
+	"errors"
+	"os"
+)
+
+var errLocked = errors.New("the file has been locked")
+
+func flock(*os.File) error { return errors.ErrUnsupported }
+
+// processExists always returns true, so the existing pid file
+// is never regarded as stale.
+func processExists(pid int) bool { return pid > 0 }
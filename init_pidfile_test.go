@@ -0,0 +1,138 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package goapp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/xgfone/gconf/v6"
+	"github.com/xgfone/go-toolkit/app"
+)
+
+// deadPid is the pid which is larger than the maximum pid of the system.
+const deadPid = 1<<31 - 1
+
+func TestAcquireLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.lock")
+
+	file, err := acquireLockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = writeLockFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := readPidFile(path); err != nil || pid != os.Getpid() {
+		t.Errorf("expect the pid %d in the lock file, but got %d, %v", os.Getpid(), pid, err)
+	}
+
+	// Each open of the file is locked independently by flock.
+	expect := fmt.Sprintf("another instance (pid %d) is running", os.Getpid())
+	if _, err := acquireLockFile(path); err == nil || !strings.Contains(err.Error(), expect) {
+		t.Errorf("expect the error '%s', but got %v", expect, err)
+	}
+
+	file.Close()
+	if file, err = acquireLockFile(path); err != nil {
+		t.Errorf("expect to acquire the released lock, but got %v", err)
+	} else {
+		file.Close()
+	}
+}
+
+func TestWritePidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.pid")
+
+	// The pid file of the crashed process is stale.
+	if err := os.WriteFile(path, []byte(strconv.Itoa(deadPid)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePidFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := readPidFile(path); err != nil || pid != os.Getpid() {
+		t.Errorf("expect the pid %d, but got %d, %v", os.Getpid(), pid, err)
+	}
+
+	// The pid file of the running process.
+	ppid := os.Getppid()
+	if err := os.WriteFile(path, []byte(strconv.Itoa(ppid)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expect := fmt.Sprintf("another instance (pid %d) is running", ppid)
+	if err := writePidFile(path); err == nil || !strings.Contains(err.Error(), expect) {
+		t.Errorf("expect the error '%s', but got %v", expect, err)
+	}
+
+	// The pid file written by another process is not removed.
+	removePidFile(path)
+	if !fileExists(path) {
+		t.Errorf("expect the pid file of another process to be kept")
+	}
+}
+
+func TestAcquireAndReleaseInstance(t *testing.T) {
+	dir := t.TempDir()
+	lockpath, pidpath := filepath.Join(dir, "app.lock"), filepath.Join(dir, "app.pid")
+	setOpt(t, lockfile.Name, lockpath)
+	setOpt(t, pidfile.Name, pidpath)
+
+	if err := acquireInstance(context.Background(), app.DefaultApp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acquireLockFile(lockpath); err == nil {
+		t.Errorf("expect the lock to be held by the instance")
+	}
+	if !fileExists(pidpath) {
+		t.Errorf("expect the pid file '%s'", pidpath)
+	}
+
+	if err := releaseInstance(context.Background(), app.DefaultApp); err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(pidpath) {
+		t.Errorf("expect the pid file to be removed")
+	}
+	if !fileExists(lockpath) {
+		t.Errorf("expect the lock file to be kept")
+	}
+
+	if file, err := acquireLockFile(lockpath); err != nil {
+		t.Errorf("expect the lock to be released, but got %v", err)
+	} else {
+		file.Close()
+	}
+}
+
+func setOpt(t *testing.T, name string, value any) {
+	origin := gconf.Get(name)
+	if err := gconf.Set(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { gconf.Set(name, origin) })
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
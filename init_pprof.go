@@ -0,0 +1,120 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The pprof handlers are implemented based on runtime/pprof instead of
+// net/http/pprof, which registers its handlers into http.DefaultServeMux
+// when imported, so that pprof is only exposed by the admin server.
+
+func pprofIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "Profiles:")
+	for _, p := range pprof.Profiles() {
+		fmt.Fprintf(w, "  %-12s %d\t/debug/pprof/%s?debug=1\n", p.Name(), p.Count(), p.Name())
+	}
+	fmt.Fprintln(w, "  cmdline\t\t/debug/pprof/cmdline")
+	fmt.Fprintln(w, "  profile\t\t/debug/pprof/profile?seconds=30")
+	fmt.Fprintln(w, "  trace\t\t\t/debug/pprof/trace?seconds=1")
+}
+
+func pprofCmdline(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, strings.Join(os.Args, "\x00"))
+}
+
+// pprofProfile serves the named profile, such as "heap" and "goroutine",
+// which supports the query arguments "debug" and "gc".
+func pprofProfile(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	p := pprof.Lookup(name)
+	if p == nil {
+		http.Error(w, fmt.Sprintf("unknown profile '%s'", name), http.StatusNotFound)
+		return
+	}
+
+	debug, _ := strconv.Atoi(r.FormValue("debug"))
+	if name == "heap" && r.FormValue("gc") != "" {
+		runtime.GC()
+	}
+
+	if debug != 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	}
+	p.WriteTo(w, debug)
+}
+
+// pprofCPU serves the CPU profile for the duration specified by
+// the query argument "seconds", which is 30 by default.
+func pprofCPU(w http.ResponseWriter, r *http.Request) {
+	duration := getProfileDuration(r, 30)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := pprof.StartCPUProfile(w); err != nil {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, "fail to enable CPU profiling: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sleep(r, duration)
+	pprof.StopCPUProfile()
+}
+
+// pprofTrace serves the execution trace for the duration specified by
+// the query argument "seconds", which is 1 by default.
+func pprofTrace(w http.ResponseWriter, r *http.Request) {
+	duration := getProfileDuration(r, 1)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	if err := trace.Start(w); err != nil {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, "fail to enable tracing: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sleep(r, duration)
+	trace.Stop()
+}
+
+func getProfileDuration(r *http.Request, _default float64) time.Duration {
+	seconds, err := strconv.ParseFloat(r.FormValue("seconds"), 64)
+	if err != nil || seconds <= 0 {
+		seconds = _default
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func sleep(r *http.Request, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-r.Context().Done():
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/gconf/v6"
+	"github.com/xgfone/go-toolkit/app"
+)
+
+// inheritedFilesEnv is the environment variable passing the names
+// of the files inherited from the parent process, which are separated
+// by comma and correspond to the file descriptors from 3 in order.
+//
+// The name is one of
+//
+//	listener:NETWORK:ADDRESS
+//	lock:PATH
+//	ready
+const inheritedFilesEnv = "GOAPP_INHERITED_FILES"
+
+var restarttimeout = gconf.DurationOpt("app.restarttimeout",
+	"The maximum duration to wait for the new process to be ready when restarting gracefully.").
+	D(time.Minute)
+
+var (
+	inheritonce sync.Once
+	inheritlock sync.Mutex
+	inherited   map[string]*os.File
+	restarted   bool
+
+	listenlock sync.Mutex
+	listeners  = make(map[string]net.Listener)
+
+	restarting atomic.Bool
+)
+
+func init() {
+	gconf.RegisterOpts(restarttimeout)
+
+	app.StageReady.On(notifyParentReady)
+}
+
+// notifyParentReady notifies the parent process of the readiness
+// if started by Restart, then takes over the instance files from it.
+func notifyParentReady(context.Context, *app.App) error {
+	if !isRestarted() {
+		return nil
+	}
+
+	if ready := inheritedFile("ready"); ready != nil {
+		_, err := ready.Write([]byte{1})
+		ready.Close()
+
+		if err != nil {
+			slog.Error("fail to notify the parent process of the readiness", "err", err)
+		} else if err = writeInstanceFiles(); err != nil {
+			slog.Error("fail to take over the instance files", "err", err)
+		}
+	}
+
+	// Close the inherited files not used by the new process.
+	inheritlock.Lock()
+	for name, file := range inherited {
+		slog.Warn("close the unused inherited file", "name", name)
+		file.Close()
+	}
+	inherited = nil
+	inheritlock.Unlock()
+	return nil
+}
+
+func loadInheritedFiles() {
+	names := os.Getenv(inheritedFilesEnv)
+	if names == "" {
+		return
+	}
+	os.Unsetenv(inheritedFilesEnv)
+
+	restarted = true
+	inherited = make(map[string]*os.File)
+	for i, name := range strings.Split(names, ",") {
+		inherited[name] = os.NewFile(uintptr(3+i), name)
+	}
+}
+
+// isRestarted reports whether the current process is started by Restart.
+func isRestarted() bool {
+	inheritonce.Do(loadInheritedFiles)
+	return restarted
+}
+
+// inheritedFile takes the file named name inherited from the parent process.
+//
+// Return nil if not found.
+func inheritedFile(name string) (file *os.File) {
+	inheritonce.Do(loadInheritedFiles)
+
+	inheritlock.Lock()
+	defer inheritlock.Unlock()
+	if file = inherited[name]; file != nil {
+		delete(inherited, name)
+	}
+	return
+}
+
+// listen is the same as net.Listen, but uses the listener inherited
+// from the parent process first, and records the listener to be passed
+// to the new process by Restart.
+func listen(network, addr string) (ln net.Listener, err error) {
+	name := "listener:" + network + ":" + addr
+	if file := inheritedFile(name); file != nil {
+		ln, err = net.FileListener(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("fail to inherit the listener on '%s': %w", addr, err)
+		}
+		slog.Info("inherit the listener from the parent process", "network", network, "addr", addr)
+	} else if ln, err = net.Listen(network, addr); err != nil {
+		return
+	}
+
+	listenlock.Lock()
+	listeners[name] = ln
+	listenlock.Unlock()
+	return
+}
+
+// Restart restarts the app gracefully without dropping the connections.
+//
+// It starts a new process with the same executable, arguments
+// and environments, and passes the listening sockets and the instance
+// lock to it. After the new process is ready, the current process
+// notifies systemd of the new main pid, then is stopped gracefully
+// to drain the connections.
+//
+// If the new process is not ready within the option "app.restarttimeout",
+// it is killed and the current process continues to run, which rewrites
+// its process id into the pid file and the lock file.
+//
+// On Unix, it is also called when receiving SIGUSR2
+// if the option "app.restartsignal" is enabled.
+func Restart() (err error) {
+	if !restarting.CompareAndSwap(false, true) {
+		return errors.New("the app is restarting")
+	}
+	defer restarting.Store(false)
+
+	var names []string
+	var files []*os.File
+	defer func() {
+		for _, file := range files {
+			if file != lockedfile {
+				file.Close()
+			}
+		}
+	}()
+
+	listenlock.Lock()
+	for name, ln := range listeners {
+		f, ok := ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+
+		file, _err := f.File()
+		if _err != nil {
+			listenlock.Unlock()
+			return fmt.Errorf("fail to get the file of the listener '%s': %w", name, _err)
+		}
+
+		names = append(names, name)
+		files = append(files, file)
+	}
+	listenlock.Unlock()
+
+	if lockedfile != nil {
+		names = append(names, "lock:"+lockedfile.Name())
+		files = append(files, lockedfile)
+	}
+
+	readyr, readyw, err := os.Pipe()
+	if err != nil {
+		return
+	}
+	defer readyr.Close()
+	names = append(names, "ready")
+	files = append(files, readyw)
+
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = restartEnv(names)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("fail to start the new process: %w", err)
+	}
+	slog.Info("start the new process to restart", "pid", cmd.Process.Pid)
+
+	// Close the write end in the current process, so reading it
+	// returns io.EOF when the new process exits.
+	readyw.Close()
+
+	ready := make(chan error, 1)
+	go func() {
+		_, err := readyr.Read(make([]byte, 1))
+		ready <- err
+	}()
+
+	timeout := gconf.GetDuration(restarttimeout.Name)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-ready:
+		if err != nil {
+			go cmd.Wait()
+			restoreInstanceFiles()
+			return fmt.Errorf("the new process exits before being ready: %w", err)
+		}
+
+	case <-timer.C:
+		// Wait for the killed process to exit before restoring the files,
+		// which it may be writing after being ready just now.
+		cmd.Process.Kill()
+		cmd.Wait()
+		restoreInstanceFiles()
+		return fmt.Errorf("the new process is not ready in %s", timeout)
+	}
+
+	slog.Info("the new process is ready, and stop the current process", "pid", cmd.Process.Pid)
+
+	// Only the main process is allowed to change the main pid
+	// by the default "NotifyAccess=main" of systemd.
+	notifySystemd("MAINPID=" + strconv.Itoa(cmd.Process.Pid))
+
+	go cmd.Wait()
+	stopApp()
+	return
+}
+
+// restartEnv returns the environments of the new process started by Restart.
+//
+// WATCHDOG_PID set by systemd is the pid of the current process, which
+// disables the watchdog of the new process. So remove it, and the new process
+// pings the watchdog as the main process after taking over.
+func restartEnv(names []string) []string {
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, "WATCHDOG_PID=")
+	})
+	return append(env, inheritedFilesEnv+"="+strings.Join(names, ","))
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package goapp
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xgfone/gconf/v6"
+	"github.com/xgfone/go-toolkit/app"
+	"github.com/xgfone/goapp/systemd"
+)
+
+// The test binary is started again by Restart as the new process,
+// which runs as the child with the mode and the directory
+// of the instance files in the environment variables.
+const (
+	restartModeEnv = "GOAPP_TEST_RESTART_MODE"
+	restartDirEnv  = "GOAPP_TEST_RESTART_DIR"
+)
+
+const restartAddr = "127.0.0.1:0"
+
+func init() {
+	if mode := os.Getenv(restartModeEnv); mode != "" {
+		os.Exit(runRestartChild(mode, os.Getenv(restartDirEnv)))
+	}
+}
+
+func runRestartChild(mode, dir string) int {
+	_ = gconf.Set(lockfile.Name, filepath.Join(dir, "app.lock"))
+	_ = gconf.Set(pidfile.Name, filepath.Join(dir, "app.pid"))
+	if err := acquireInstance(context.Background(), app.DefaultApp); err != nil {
+		return 1
+	}
+
+	// The new process fails to start.
+	if mode == "fail" {
+		return 1
+	}
+
+	ln, err := listen("tcp", restartAddr)
+	if err != nil {
+		return 1
+	}
+	_, _ = systemd.Status(ln.Addr().String())
+
+	if interval, _ := systemd.WatchdogInterval(); interval > 0 {
+		_, _ = systemd.Notify(systemd.Watchdog)
+	}
+
+	_ = notifyParentReady(context.Background(), app.DefaultApp)
+	return 0
+}
+
+// setupRestart sets up the parent process to be restarted,
+// and returns the fake socket of systemd and the directory
+// of the instance files.
+func setupRestart(t *testing.T, mode string) (notify *net.UnixConn, dir string) {
+	dir = t.TempDir()
+
+	socket := filepath.Join(dir, "notify.sock")
+	notify, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { notify.Close() })
+
+	t.Setenv("NOTIFY_SOCKET", socket)
+	t.Setenv("WATCHDOG_USEC", "60000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv(restartModeEnv, mode)
+	t.Setenv(restartDirEnv, dir)
+
+	setOpt(t, lockfile.Name, filepath.Join(dir, "app.lock"))
+	setOpt(t, pidfile.Name, filepath.Join(dir, "app.pid"))
+	if err := acquireInstance(context.Background(), app.DefaultApp); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { releaseInstance(context.Background(), app.DefaultApp) })
+
+	return
+}
+
+func TestRestart(t *testing.T) {
+	notify, dir := setupRestart(t, "ready")
+
+	ln, err := listen("tcp", restartAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		listenlock.Lock()
+		delete(listeners, "listener:tcp:"+restartAddr)
+		listenlock.Unlock()
+		ln.Close()
+	})
+
+	if err := Restart(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The new process inherits the listener, pings the watchdog,
+	// and becomes the main process after being ready.
+	var pid string
+	expects := []string{"STATUS=" + ln.Addr().String(), systemd.Watchdog, "MAINPID="}
+	for _, expect := range expects {
+		buf := make([]byte, 64)
+		_ = notify.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := notify.Read(buf)
+		if err != nil {
+			t.Fatalf("expect the message '%s', but got %v", expect, err)
+		}
+
+		msg := string(buf[:n])
+		if !strings.HasPrefix(msg, expect) {
+			t.Errorf("expect the message '%s', but got '%s'", expect, msg)
+		}
+		if expect == "MAINPID=" {
+			pid = strings.TrimPrefix(msg, expect)
+		}
+	}
+
+	// The new process writes the instance files after being ready.
+	for _, name := range []string{"app.pid", "app.lock"} {
+		path := filepath.Join(dir, name)
+		var data []byte
+		for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond * 10) {
+			if data, _ = os.ReadFile(path); strings.TrimSpace(string(data)) == pid {
+				break
+			}
+		}
+		if got := strings.TrimSpace(string(data)); got != pid {
+			t.Errorf("%s: expect the pid %s, but got '%s'", name, pid, got)
+		}
+	}
+}
+
+func TestRestartFail(t *testing.T) {
+	_, dir := setupRestart(t, "fail")
+
+	err := Restart()
+	if err == nil || !strings.Contains(err.Error(), "exits before being ready") {
+		t.Fatalf("expect the new process to fail, but got %v", err)
+	}
+
+	// The instance files are still owned by the current process.
+	for _, name := range []string{"app.pid", "app.lock"} {
+		if pid, err := readPidFile(filepath.Join(dir, name)); err != nil || pid != os.Getpid() {
+			t.Errorf("%s: expect the pid %d, but got %d, %v", name, os.Getpid(), pid, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package goapp
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"syscall"
+
+	"github.com/xgfone/gconf/v6"
+	"github.com/xgfone/go-toolkit/app"
+)
+
+var restartsignal = gconf.BoolOpt("app.restartsignal",
+	"Whether to restart the app gracefully when receiving SIGUSR2.")
+
+func init() {
+	gconf.RegisterOpts(restartsignal)
+
+	app.StageInit.On(func(context.Context, *app.App) error {
+		if gconf.GetBool(restartsignal.Name) {
+			OnSignal(restartBySignal, syscall.SIGUSR2)
+		}
+		return nil
+	})
+}
+
+func restartBySignal(os.Signal) {
+	go func() {
+		if err := Restart(); err != nil {
+			slog.Error("fail to restart the app", "err", err)
+		}
+	}()
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xgfone/goapp/cron"
+)
+
+// OverlapPolicy is the policy when a job is triggered
+// but its previous run has not finished.
+type OverlapPolicy int
+
+// Predefine some overlap policies.
+const (
+	OverlapSkip  OverlapPolicy = iota // Skip the triggered run.
+	OverlapQueue                      // Run it after the previous run, and at most one run is queued.
+	OverlapAllow                      // Run it concurrently with the previous run.
+)
+
+// Job is a periodic job scheduled by the cron expression or the fixed interval.
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+
+	// Cron is the cron expression to schedule the job, see cron.Parse.
+	//
+	// Either Cron or Interval must be set, and Cron takes precedence.
+	Cron string
+
+	// Interval is the fixed interval to schedule the job.
+	Interval time.Duration
+
+	// Jitter is the maximum random delay added to each scheduled time,
+	// which is used to avoid the thundering herd.
+	Jitter time.Duration
+
+	// Timeout is the maximum duration of each run.
+	// If 0, there is no timeout.
+	Timeout time.Duration
+
+	// Overlap is the policy when the previous run has not finished.
+	//
+	// Default: OverlapSkip
+	Overlap OverlapPolicy
+}
+
+// RegisterJob registers a periodic job, which is scheduled after the app
+// is ready, and stopped with the background tasks when the app is stopping.
+//
+// The context passed to the job is cancelled when the app is stopping
+// or the run times out.
+func RegisterJob(job Job) error {
+	s, err := newScheduler(job)
+	if err != nil {
+		return err
+	}
+
+	RegisterTask(Task{Name: "job:" + job.Name, Run: s.schedule, Restart: RestartOnFailure})
+	return nil
+}
+
+type scheduler struct {
+	job     Job
+	next    func(time.Time) time.Time
+	queue   chan struct{}
+	running atomic.Bool
+	wg      sync.WaitGroup
+}
+
+func newScheduler(job Job) (*scheduler, error) {
+	if job.Name == "" {
+		return nil, errors.New("the job name must not be empty")
+	}
+	if job.Run == nil {
+		return nil, fmt.Errorf("the function of the job '%s' must not be nil", job.Name)
+	}
+
+	var next func(time.Time) time.Time
+	switch {
+	case job.Cron != "":
+		schedule, err := cron.Parse(job.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression of the job '%s': %w", job.Name, err)
+		}
+		next = schedule.Next
+
+	case job.Interval > 0:
+		next = func(now time.Time) time.Time { return now.Add(job.Interval) }
+
+	default:
+		return nil, fmt.Errorf("the job '%s' has neither cron nor interval", job.Name)
+	}
+
+	s := &scheduler{job: job, next: next}
+	if job.Overlap == OverlapQueue {
+		s.queue = make(chan struct{}, 1)
+	}
+	return s, nil
+}
+
+func (s *scheduler) schedule(ctx context.Context) error {
+	defer s.wg.Wait()
+
+	if s.queue != nil {
+		s.wg.Add(1)
+		go s.consume(ctx)
+	}
+
+	var last time.Time
+	for {
+		// The timer may fire before the scheduled time by the wall clock,
+		// so never schedule the job earlier than the last scheduled time.
+		now := time.Now()
+		base := now
+		if base.Before(last) {
+			base = last
+		}
+
+		next := s.next(base)
+		if next.IsZero() {
+			slog.Warn("the job has no next time to run", "job", s.job.Name, "cron", s.job.Cron)
+			<-ctx.Done()
+			return nil
+		}
+		last = next
+
+		if s.job.Jitter > 0 {
+			next = next.Add(rand.N(s.job.Jitter))
+		}
+
+		timer := time.NewTimer(max(next.Sub(now), 0))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+			s.trigger(ctx)
+		}
+	}
+}
+
+func (s *scheduler) trigger(ctx context.Context) {
+	switch s.job.Overlap {
+	case OverlapQueue:
+		select {
+		case s.queue <- struct{}{}:
+		default:
+			slog.Warn("skip the job since a run has been queued", "job", s.job.Name)
+		}
+
+	case OverlapAllow:
+		s.wg.Add(1)
+		go func() { defer s.wg.Done(); s.run(ctx) }()
+
+	default:
+		if !s.running.CompareAndSwap(false, true) {
+			slog.Warn("skip the job since the previous run has not finished", "job", s.job.Name)
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.running.Store(false)
+			s.run(ctx)
+		}()
+	}
+}
+
+func (s *scheduler) consume(ctx context.Context) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.queue:
+			s.run(ctx)
+		}
+	}
+}
+
+func (s *scheduler) run(ctx context.Context) {
+	if s.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("the job panics", "job", s.job.Name, "cost", time.Since(start),
+				"panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	slog.Debug("start to run the job", "job", s.job.Name)
+	if err := s.job.Run(ctx); err != nil {
+		slog.Error("fail to run the job", "job", s.job.Name, "cost", time.Since(start), "err", err)
+	} else {
+		slog.Info("finish running the job", "job", s.job.Name, "cost", time.Since(start))
+	}
+}
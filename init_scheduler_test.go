@@ -0,0 +1,189 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startScheduler starts to schedule the job, and returns the function
+// to stop the scheduler and wait for all the runs to finish.
+func startScheduler(t *testing.T, job Job) (stop func()) {
+	s, err := newScheduler(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { defer close(done); s.schedule(ctx) }()
+
+	return func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("the scheduler fails to stop in time")
+		}
+	}
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	tests := []struct {
+		overlap OverlapPolicy
+		runs    int32
+	}{
+		// The first run blocks the next two triggers.
+		{OverlapSkip, 1},  // Both triggers are skipped.
+		{OverlapQueue, 2}, // One trigger is queued, and the other is skipped.
+		{OverlapAllow, 3}, // Both triggers run concurrently.
+	}
+
+	for _, test := range tests {
+		var runs atomic.Int32
+		started := make(chan struct{}, 3)
+		release := make(chan struct{})
+		s, err := newScheduler(Job{
+			Name:     "test",
+			Interval: time.Hour,
+			Overlap:  test.overlap,
+			Run: func(context.Context) error {
+				started <- struct{}{}
+				if runs.Add(1) == 1 {
+					<-release
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Drive the triggers directly instead of waiting for the ticks,
+		// so that the result does not depend on the timing.
+		ctx, cancel := context.WithCancel(context.Background())
+		if s.queue != nil {
+			s.wg.Add(1)
+			go s.consume(ctx)
+		}
+
+		s.trigger(ctx)
+		<-started
+		s.trigger(ctx)
+		s.trigger(ctx)
+		close(release)
+
+		for i := int32(1); i < test.runs; i++ {
+			select {
+			case <-started:
+			case <-time.After(time.Second):
+				t.Errorf("overlap=%d: expect %d runs, but got %d", test.overlap, test.runs, runs.Load())
+			}
+		}
+
+		cancel()
+		s.wg.Wait()
+		if n := runs.Load(); n != test.runs {
+			t.Errorf("overlap=%d: expect %d runs, but got %d", test.overlap, test.runs, n)
+		}
+	}
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	const interval = time.Millisecond * 20
+	const jitter = time.Millisecond * 40
+
+	var lock sync.Mutex
+	var starts []time.Time
+	stop := startScheduler(t, Job{
+		Name:     "test",
+		Interval: interval,
+		Jitter:   jitter,
+		Run: func(context.Context) error {
+			lock.Lock()
+			starts = append(starts, time.Now())
+			lock.Unlock()
+			return nil
+		},
+	})
+
+	time.Sleep(time.Millisecond * 500)
+	stop()
+
+	if len(starts) < 3 {
+		t.Fatalf("expect at least 3 runs, but got %d", len(starts))
+	}
+
+	var total time.Duration
+	for i := 1; i < len(starts); i++ {
+		// Allow the latency to start the goroutine of the previous run.
+		delay := starts[i].Sub(starts[i-1])
+		if delay < interval-interval/2 {
+			t.Errorf("run %d: expect the delay of at least %s, but got %s", i, interval, delay)
+		}
+		total += delay
+	}
+
+	// The mean of the jitter is jitter/2.
+	if mean := total / time.Duration(len(starts)-1); mean < interval+jitter/4 {
+		t.Errorf("expect the jitter to delay the runs, but got the mean delay %s", mean)
+	}
+}
+
+func TestSchedulerTimeout(t *testing.T) {
+	errs := make(chan error, 1)
+	stop := startScheduler(t, Job{
+		Name:     "test",
+		Interval: time.Millisecond * 10,
+		Timeout:  time.Millisecond * 20,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			select {
+			case errs <- ctx.Err():
+			default:
+			}
+			return ctx.Err()
+		},
+	})
+	defer stop()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expect the run to time out, but got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expect the run to time out")
+	}
+}
+
+func TestNewSchedulerError(t *testing.T) {
+	run := func(context.Context) error { return nil }
+	for _, job := range []Job{
+		{Run: run, Interval: time.Second},
+		{Name: "test", Interval: time.Second},
+		{Name: "test", Run: run},
+		{Name: "test", Run: run, Cron: "* * *"},
+	} {
+		if _, err := newScheduler(job); err == nil {
+			t.Errorf("expect an error for the job %+v", job)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/xgfone/gconf/v6"
+	"github.com/xgfone/goapp/log"
+)
+
+var (
+	signallock     sync.Mutex
+	signalonce     sync.Once
+	signalch       = make(chan os.Signal, 8)
+	signalhandlers = make(map[os.Signal][]func(os.Signal))
+)
+
+// OnSignal registers the handler called when receiving any of the signals.
+//
+// The handlers are called one by one in a separate goroutine in order,
+// so they should not block for a long time.
+//
+// Notice: the exit signals, such as SIGINT and SIGTERM, have been handled
+// to stop the app, so they should not be registered again.
+func OnSignal(handler func(os.Signal), sigs ...os.Signal) {
+	if handler == nil {
+		panic("OnSignal: the signal handler must not be nil")
+	}
+	if len(sigs) == 0 {
+		panic("OnSignal: the signals must not be empty")
+	}
+
+	signallock.Lock()
+	defer signallock.Unlock()
+	for _, sig := range sigs {
+		if _, ok := signalhandlers[sig]; !ok {
+			signal.Notify(signalch, sig)
+		}
+		signalhandlers[sig] = append(signalhandlers[sig], handler)
+	}
+
+	signalonce.Do(func() { go handleSignals() })
+}
+
+func handleSignals() {
+	for sig := range signalch {
+		signallock.Lock()
+		handlers := signalhandlers[sig]
+		signallock.Unlock()
+
+		slog.Info("receive the signal", "signal", sig.String())
+		for _, handler := range handlers {
+			callSignalHandler(handler, sig)
+		}
+	}
+}
+
+func callSignalHandler(handler func(os.Signal), sig os.Signal) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("the signal handler panics", "signal", sig.String(),
+				"panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	handler(sig)
+}
+
+// reload reloads the config file and reopens the log file.
+func reload(os.Signal) {
+	if cfile := gconf.GetString(gconf.ConfigFileOpt.Name); cfile != "" {
+		if err := gconf.LoadSource(gconf.NewFileSource(cfile), true); err != nil {
+			slog.Error("fail to reload the config file", "file", cfile, "err", err)
+		} else {
+			slog.Info("reload the config file", "file", cfile)
+		}
+	}
+
+	if err := log.Reopen(); err != nil {
+		slog.Error("fail to reopen the log file", "err", err)
+	}
+}
+
+// dumpDiagnostics dumps the stacks of all the goroutines and the heap profile
+// into the files in the current working directory.
+func dumpDiagnostics(os.Signal) {
+	suffix := fmt.Sprintf("%d-%s", os.Getpid(), time.Now().Format("20060102T150405"))
+	for _, dump := range []struct {
+		profile  string
+		filename string
+		debug    int
+	}{
+		{profile: "goroutine", filename: "goroutine-" + suffix + ".txt", debug: 2},
+		{profile: "heap", filename: "heap-" + suffix + ".pprof", debug: 0},
+	} {
+		if err := writeProfile(dump.profile, dump.filename, dump.debug); err != nil {
+			slog.Error("fail to dump the profile", "profile", dump.profile, "file", dump.filename, "err", err)
+		} else {
+			slog.Info("dump the profile", "profile", dump.profile, "file", dump.filename)
+		}
+	}
+}
+
+func writeProfile(profile, filename string, debug int) (err error) {
+	p := pprof.Lookup(profile)
+	if p == nil {
+		return fmt.Errorf("no profile named '%s'", profile)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return
+	}
+
+	err = p.WriteTo(file, debug)
+	if _err := file.Close(); err == nil {
+		err = _err
+	}
+	if err != nil {
+		err = errors.Join(err, os.Remove(filename))
+	}
+	return
+}
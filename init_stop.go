@@ -0,0 +1,134 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Predefine some priorities of the stop hooks.
+const (
+	StopPriorityFirst   = 1000 // Such as marking the app not ready.
+	StopPriorityServer  = 500  // Such as the servers accepting the requests.
+	StopPriorityTask    = 300  // Such as the background tasks.
+	StopPriorityDefault = 0    // Such as the clients of the databases.
+)
+
+type stophook struct {
+	name     string
+	priority int
+	stop     func(context.Context) error
+}
+
+var (
+	stoplock  sync.Mutex
+	stophooks []stophook
+	stoponce  sync.Once
+)
+
+// OnStop registers a stop hook named name, which is called with the context
+// having the shutdown deadline when the app is stopping.
+//
+// The hooks are called in descending order of the priority, and the hooks
+// with the same priority are called concurrently. So a dependent should have
+// a higher priority than its dependencies to be stopped before them.
+func OnStop(name string, priority int, stop func(context.Context) error) {
+	if stop == nil {
+		panic("OnStop: the stop function must not be nil")
+	}
+
+	stoplock.Lock()
+	defer stoplock.Unlock()
+	stophooks = append(stophooks, stophook{name: name, priority: priority, stop: stop})
+}
+
+// shutdown runs the stop hooks with the shutdown deadline.
+func shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout())
+	defer cancel()
+	runStopHooks(ctx)
+}
+
+// runStopHooks runs the stop hooks only once, and the later calls will
+// wait for the first to finish.
+func runStopHooks(ctx context.Context) {
+	stoponce.Do(func() {
+		stoplock.Lock()
+		hooks := slices.Clone(stophooks)
+		stoplock.Unlock()
+		stopAllHooks(ctx, hooks)
+	})
+}
+
+// stopAllHooks runs the hooks in descending order of the priority,
+// and runs the hooks with the same priority concurrently.
+func stopAllHooks(ctx context.Context, hooks []stophook) {
+	slices.SortStableFunc(hooks, func(a, b stophook) int { return b.priority - a.priority })
+	for start := 0; start < len(hooks); {
+		end := start + 1
+		for end < len(hooks) && hooks[end].priority == hooks[start].priority {
+			end++
+		}
+
+		stopHooks(ctx, hooks[start:end])
+		start = end
+	}
+}
+
+func stopHooks(ctx context.Context, hooks []stophook) {
+	type result struct {
+		index int
+		cost  time.Duration
+		err   error
+	}
+
+	results := make(chan result, len(hooks))
+	for i, hook := range hooks {
+		go func(index int, hook stophook) {
+			start := time.Now()
+			err := hook.stop(ctx)
+			results <- result{index: index, cost: time.Since(start), err: err}
+		}(i, hook)
+	}
+
+	stopped := make([]bool, len(hooks))
+	for range hooks {
+		select {
+		case r := <-results:
+			hook := hooks[r.index]
+			stopped[r.index] = true
+			if r.err != nil {
+				slog.Error("fail to stop the component", "name", hook.name,
+					"priority", hook.priority, "cost", r.cost, "err", r.err)
+			} else {
+				slog.Debug("stop the component", "name", hook.name,
+					"priority", hook.priority, "cost", r.cost)
+			}
+
+		case <-ctx.Done():
+			for i, hook := range hooks {
+				if !stopped[i] {
+					slog.Error("the component fails to stop in time",
+						"name", hook.name, "priority", hook.priority)
+				}
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStopHooksOrder(t *testing.T) {
+	var lock sync.Mutex
+	var stopped []string
+	hook := func(name string, priority int) stophook {
+		return stophook{name: name, priority: priority, stop: func(context.Context) error {
+			lock.Lock()
+			stopped = append(stopped, name)
+			lock.Unlock()
+			return nil
+		}}
+	}
+
+	stopAllHooks(context.Background(), []stophook{
+		hook("db", StopPriorityDefault),
+		hook("server", StopPriorityServer),
+		hook("task", StopPriorityTask),
+		hook("ready", StopPriorityFirst),
+		hook("cache", -1),
+	})
+
+	expects := []string{"ready", "server", "task", "db", "cache"}
+	if !slices.Equal(stopped, expects) {
+		t.Errorf("expect the stop order %v, but got %v", expects, stopped)
+	}
+}
+
+func TestStopHooksConcurrent(t *testing.T) {
+	// The hooks with the same priority wait for each other,
+	// so they would deadlock if run in turn.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	hook := func(name string) stophook {
+		return stophook{name: name, stop: func(ctx context.Context) error {
+			wg.Done()
+			done := make(chan struct{})
+			go func() { wg.Wait(); close(done) }()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}}
+	}
+
+	var after bool
+	hooks := []stophook{hook("a"), hook("b"), {name: "c", priority: -1, stop: func(context.Context) error {
+		after = true
+		return nil
+	}}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	buf := captureLogs(t)
+	stopAllHooks(ctx, hooks)
+	if ctx.Err() != nil {
+		t.Errorf("expect the hooks with the same priority to run concurrently, but got: %s", buf)
+	}
+	if !after {
+		t.Errorf("expect the hook with the lower priority to run")
+	}
+}
+
+func TestStopHooksTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	hooks := []stophook{
+		{name: "slow", priority: 1, stop: func(context.Context) error { <-release; return nil }},
+		{name: "fast", priority: 1, stop: func(context.Context) error { return nil }},
+		{name: "fail", priority: 1, stop: func(context.Context) error { return errors.New("test") }},
+		{name: "later", stop: func(context.Context) error { <-release; return nil }},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	buf := captureLogs(t)
+	start := time.Now()
+	stopAllHooks(ctx, hooks)
+	if cost := time.Since(start); cost > time.Second*5 {
+		t.Errorf("expect to return after the deadline, but cost %s", cost)
+	}
+
+	logs := buf.String()
+	if !strings.Contains(logs, `msg="the component fails to stop in time" name=slow`) {
+		t.Errorf("expect the slow hook to be logged, but got: %s", logs)
+	}
+	if !strings.Contains(logs, `msg="fail to stop the component" name=fail`) {
+		t.Errorf("expect the failed hook to be logged, but got: %s", logs)
+	}
+	if !strings.Contains(logs, `msg="the component fails to stop in time" name=later`) {
+		t.Errorf("expect the later hook to be logged, but got: %s", logs)
+	}
+	if strings.Contains(logs, `in time" name=fast`) {
+		t.Errorf("unexpected the fast hook logged: %s", logs)
+	}
+}
+
+// captureLogs captures the logs of the default logger during the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(logger) })
+	return buf
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/xgfone/go-toolkit/app"
+	"github.com/xgfone/goapp/systemd"
+)
+
+func init() {
+	app.StageReady.On(func(context.Context, *app.App) error {
+		notifySystemd(systemd.Ready)
+		startSystemdWatchdog()
+		return nil
+	})
+
+	OnStop("systemd", StopPriorityFirst, func(context.Context) error {
+		notifySystemd(systemd.Stopping)
+		return nil
+	})
+}
+
+// NotifyStatus sends the status message to systemd if running
+// as the service of systemd with "Type=notify".
+func NotifyStatus(status string) {
+	notifySystemd("STATUS=" + status)
+}
+
+func notifySystemd(state string) {
+	if sent, err := systemd.Notify(state); err != nil {
+		slog.Error("fail to notify systemd", "state", state, "err", err)
+	} else if sent {
+		slog.Debug("notify systemd", "state", state)
+	}
+}
+
+func startSystemdWatchdog() {
+	timeout, err := systemd.WatchdogInterval()
+	if err != nil {
+		slog.Error("fail to get the systemd watchdog timeout", "err", err)
+		return
+	} else if timeout <= 0 {
+		return
+	}
+
+	slog.Info("start the systemd watchdog", "timeout", timeout)
+	go func() {
+		ticker := time.NewTicker(timeout / 2)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := systemd.Notify(systemd.Watchdog); err != nil {
+				slog.Error("fail to ping the systemd watchdog", "err", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-toolkit/app"
+)
+
+// RestartPolicy is the policy to restart the task after it returns.
+type RestartPolicy int
+
+// Predefine some restart policies.
+const (
+	RestartNever     RestartPolicy = iota // Never restart the task.
+	RestartOnFailure                      // Restart the task only when it fails or panics.
+	RestartAlways                         // Always restart the task.
+)
+
+// Task is a long-lived background task supervised by the app.
+type Task struct {
+	Name string
+
+	// Run runs the task until ctx is done, which is cancelled
+	// when the app is stopping.
+	Run func(ctx context.Context) error
+
+	// Restart is the policy to restart the task after it returns.
+	//
+	// Default: RestartNever
+	Restart RestartPolicy
+
+	// MaxRestarts is the maximum number of the restarts.
+	// If 0, the task is restarted without limit.
+	MaxRestarts int
+
+	// MinBackoff and MaxBackoff are the minimum and maximum delays
+	// before restarting the task, which is doubled on each consecutive
+	// restart and reset after the task has run for MaxBackoff.
+	//
+	// Default: 1s, 1m
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+var (
+	taskctx, taskcancel = context.WithCancel(context.Background())
+
+	tasklock    sync.Mutex
+	tasks       []Task
+	taskstarted bool
+	taskwg      sync.WaitGroup
+)
+
+func init() {
+	app.StageReady.On(func(context.Context, *app.App) error {
+		tasklock.Lock()
+		defer tasklock.Unlock()
+
+		taskstarted = true
+		for _, task := range tasks {
+			startTask(task)
+		}
+		tasks = nil
+		return nil
+	})
+
+	OnStop("tasks", StopPriorityTask, stopTasks)
+}
+
+// RegisterTask registers a background task, which is started after the app
+// is ready, or immediately if the app has been ready.
+//
+// All the tasks are cancelled when the app is stopping,
+// and waited to return within the shutdown deadline.
+func RegisterTask(task Task) {
+	if task.Name == "" {
+		panic("RegisterTask: the task name must not be empty")
+	}
+	if task.Run == nil {
+		panic("RegisterTask: the task function must not be nil")
+	}
+	if task.MinBackoff <= 0 {
+		task.MinBackoff = time.Second
+	}
+	if task.MaxBackoff <= 0 {
+		task.MaxBackoff = time.Minute
+	}
+	if task.MaxBackoff < task.MinBackoff {
+		task.MaxBackoff = task.MinBackoff
+	}
+
+	tasklock.Lock()
+	defer tasklock.Unlock()
+	if taskctx.Err() != nil {
+		slog.Warn("discard the background task since the app is stopping", "task", task.Name)
+	} else if taskstarted {
+		startTask(task)
+	} else {
+		tasks = append(tasks, task)
+	}
+}
+
+// Go is a convenient function to register the background task
+// with the restart policy RestartOnFailure.
+func Go(name string, run func(ctx context.Context) error) {
+	RegisterTask(Task{Name: name, Run: run, Restart: RestartOnFailure})
+}
+
+func startTask(task Task) {
+	taskwg.Add(1)
+	go func() {
+		defer taskwg.Done()
+		superviseTask(taskctx, task)
+	}()
+}
+
+func stopTasks(ctx context.Context) error {
+	tasklock.Lock()
+	taskcancel()
+	tasklock.Unlock()
+
+	done := make(chan struct{})
+	go func() { taskwg.Wait(); close(done) }()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("the background tasks fail to stop in time: %w", ctx.Err())
+	}
+}
+
+func superviseTask(ctx context.Context, task Task) {
+	backoff := task.MinBackoff
+	for restarts := 0; ; restarts++ {
+		slog.Info("start the background task", "task", task.Name, "restarts", restarts)
+
+		start := time.Now()
+		err := runTask(ctx, task)
+		if ctx.Err() != nil {
+			slog.Info("stop the background task", "task", task.Name, "err", err)
+			return
+		}
+
+		if err != nil {
+			slog.Error("the background task fails", "task", task.Name, "err", err)
+		} else {
+			slog.Warn("the background task returns", "task", task.Name)
+		}
+
+		switch {
+		case task.Restart == RestartNever, task.Restart == RestartOnFailure && err == nil:
+			return
+		case task.MaxRestarts > 0 && restarts >= task.MaxRestarts:
+			slog.Error("the background task reaches the maximum restarts",
+				"task", task.Name, "restarts", restarts)
+			return
+		}
+
+		if time.Since(start) >= task.MaxBackoff {
+			backoff = task.MinBackoff
+		}
+
+		slog.Info("restart the background task later", "task", task.Name, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, task.MaxBackoff)
+	}
+}
+
+func runTask(ctx context.Context, task Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			slog.Error("the background task panics", "task", task.Name,
+				"panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	return task.Run(ctx)
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goapp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSuperviseTaskRestartPolicy(t *testing.T) {
+	errTask := errors.New("test")
+	tests := []struct {
+		policy RestartPolicy
+		err    error
+		runs   int
+	}{
+		{RestartNever, nil, 1},
+		{RestartNever, errTask, 1},
+		{RestartOnFailure, nil, 1},
+		{RestartOnFailure, errTask, 4},
+		{RestartAlways, nil, 4},
+		{RestartAlways, errTask, 4},
+	}
+
+	for _, test := range tests {
+		var runs int
+		superviseTask(context.Background(), Task{
+			Name:        "test",
+			Restart:     test.policy,
+			MaxRestarts: 3,
+			MinBackoff:  time.Millisecond,
+			MaxBackoff:  time.Millisecond,
+			Run:         func(context.Context) error { runs++; return test.err },
+		})
+
+		if runs != test.runs {
+			t.Errorf("policy=%d, err=%v: expect %d runs, but got %d", test.policy, test.err, test.runs, runs)
+		}
+	}
+}
+
+func TestSuperviseTaskPanic(t *testing.T) {
+	var runs int
+	superviseTask(context.Background(), Task{
+		Name:        "test",
+		Restart:     RestartOnFailure,
+		MaxRestarts: 1,
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  time.Millisecond,
+		Run:         func(context.Context) error { runs++; panic("test") },
+	})
+
+	if runs != 2 {
+		t.Errorf("expect the panicked task to be restarted once, but got %d runs", runs)
+	}
+}
+
+func TestSuperviseTaskCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		superviseTask(ctx, Task{
+			Name:       "test",
+			Restart:    RestartAlways,
+			MinBackoff: time.Hour,
+			MaxBackoff: time.Hour,
+			Run:        func(context.Context) error { return nil },
+		})
+	}()
+
+	// Cancel the task during the backoff.
+	time.Sleep(time.Millisecond * 20)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expect the task to stop during the backoff")
+	}
+}
+
+func TestSuperviseTaskBackoff(t *testing.T) {
+	const minBackoff = time.Millisecond * 20
+	const maxBackoff = time.Millisecond * 200
+
+	var starts []time.Time
+	superviseTask(context.Background(), Task{
+		Name:        "test",
+		Restart:     RestartAlways,
+		MaxRestarts: 5,
+		MinBackoff:  minBackoff,
+		MaxBackoff:  maxBackoff,
+		Run: func(context.Context) error {
+			starts = append(starts, time.Now())
+			if len(starts) == 4 {
+				// Run long enough to reset the backoff.
+				time.Sleep(maxBackoff)
+			}
+			return nil
+		},
+	})
+
+	if len(starts) != 6 {
+		t.Fatalf("expect %d runs, but got %d", 6, len(starts))
+	}
+
+	// The backoff is doubled on each consecutive restart.
+	for i, backoff := range []time.Duration{minBackoff, minBackoff * 2, minBackoff * 4} {
+		if delay := starts[i+1].Sub(starts[i]); delay < backoff {
+			t.Errorf("restart %d: expect the delay of at least %s, but got %s", i+1, backoff, delay)
+		}
+	}
+
+	// The 4th run lasts for MaxBackoff, so the backoff is reset.
+	if delay := starts[4].Sub(starts[3]) - maxBackoff; delay < minBackoff || delay >= minBackoff*8 {
+		t.Errorf("expect the backoff to be reset to %s, but got %s", minBackoff, delay)
+	}
+	if delay := starts[5].Sub(starts[4]); delay < minBackoff*2 {
+		t.Errorf("expect the backoff to be doubled again, but got %s", delay)
+	}
+}
+
+func TestStopTasksDeadline(t *testing.T) {
+	t.Cleanup(func() {
+		tasklock.Lock()
+		taskctx, taskcancel = context.WithCancel(context.Background())
+		tasklock.Unlock()
+	})
+
+	release := make(chan struct{})
+	startTask(Task{
+		Name:       "test",
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+		Run: func(context.Context) error {
+			<-release // Ignore the cancellation.
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	if err := stopTasks(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect the deadline error, but got %v", err)
+	}
+	if taskctx.Err() == nil {
+		t.Errorf("expect the task context to be cancelled")
+	}
+
+	close(release)
+	if err := stopTasks(context.Background()); err != nil {
+		t.Errorf("expect the tasks to stop, but got %v", err)
+	}
+}
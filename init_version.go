@@ -16,8 +16,14 @@ package goapp
 
 import (
 	"context"
+	"encoding/json"
 	"expvar"
+	"fmt"
 	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
 
 	"github.com/xgfone/go-toolkit/app"
 	"github.com/xgfone/gover"
@@ -36,3 +42,141 @@ func init() {
 		return nil
 	})
 }
+
+// Dependency is a module dependency of the app.
+type Dependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Replace string `json:"replace,omitempty"`
+}
+
+// BuildInfo is the version and build information of the app.
+type BuildInfo struct {
+	Name      string       `json:"name"`
+	Version   string       `json:"version"`
+	Revision  string       `json:"revision,omitempty"`
+	Dirty     bool         `json:"dirty"`
+	BuildTime string       `json:"build_time,omitempty"`
+	GoVersion string       `json:"go_version"`
+	Platform  string       `json:"platform"`
+	Deps      []Dependency `json:"deps,omitempty"`
+}
+
+// appVersion returns the version set by the app explicitly,
+// or "" if it is still the default.
+func appVersion() string {
+	switch version := app.Version(); version {
+	case "0.0.0", gover.Text():
+		return ""
+	default:
+		return version
+	}
+}
+
+// GetBuildInfo returns the version and build information of the app.
+//
+// The version, revision and build time are from gover first,
+// which are injected by the linker flag "-X". If missing, the version
+// falls back to the version set by the app, then the module version,
+// the revision falls back to the vcs information embedded by the go
+// toolchain, and the build time falls back to the commit time.
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Name:      app.Name(),
+		Version:   gover.Version,
+		Revision:  gover.Commit,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if info.Version == "" {
+		info.Version = appVersion()
+	}
+
+	if gover.BuildTime != "" {
+		info.BuildTime = gover.GetBuildTime().Format(time.RFC3339)
+	}
+
+	binfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	if info.Version == "" && binfo.Main.Version != "(devel)" {
+		info.Version = binfo.Main.Version
+	}
+
+	for _, setting := range binfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Revision == "" {
+				info.Revision = setting.Value
+			}
+		case "vcs.modified":
+			info.Dirty = setting.Value == "true"
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+
+	info.Deps = make([]Dependency, 0, len(binfo.Deps))
+	for _, dep := range binfo.Deps {
+		d := Dependency{Path: dep.Path, Version: dep.Version}
+		if dep.Replace != nil {
+			d.Replace = dep.Replace.Path
+			if v := dep.Replace.Version; v != "" && v != "(devel)" {
+				d.Replace += "@" + dep.Replace.Version
+			}
+		}
+		info.Deps = append(info.Deps, d)
+	}
+
+	return info
+}
+
+// String returns the text format of the build information.
+func (b BuildInfo) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Name:      %s\n", b.Name)
+	fmt.Fprintf(&buf, "Version:   %s\n", b.Version)
+	fmt.Fprintf(&buf, "Revision:  %s\n", b.Revision)
+	fmt.Fprintf(&buf, "Dirty:     %t\n", b.Dirty)
+	fmt.Fprintf(&buf, "BuildTime: %s\n", b.BuildTime)
+	fmt.Fprintf(&buf, "GoVersion: %s\n", b.GoVersion)
+	fmt.Fprintf(&buf, "Platform:  %s\n", b.Platform)
+
+	if len(b.Deps) > 0 {
+		buf.WriteString("Deps:\n")
+		for _, dep := range b.Deps {
+			fmt.Fprintf(&buf, "  %s %s", dep.Path, dep.Version)
+			if dep.Replace != "" {
+				fmt.Fprintf(&buf, " => %s", dep.Replace)
+			}
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.String()
+}
+
+// printVersion prints the build information in the format "text" or "json".
+func printVersion(format string) error {
+	info := GetBuildInfo()
+	switch format {
+	case "", "text":
+		fmt.Print(info.String())
+		return nil
+
+	case "json":
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err == nil {
+			fmt.Println(string(data))
+		}
+		return err
+
+	default:
+		return fmt.Errorf("unknown version format '%s'", format)
+	}
+}
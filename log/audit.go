@@ -0,0 +1,280 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/xgfone/go-toolkit/app"
+)
+
+// ErrAuditChainBroken is returned when the hash chain of the audit file
+// is broken, that's, the audit file has been modified or truncated.
+var ErrAuditChainBroken = errors.New("audit chain is broken")
+
+// auditEntry is the format of each line in the audit file.
+type auditEntry struct {
+	Seq    uint64          `json:"seq"`
+	Prev   string          `json:"prev"`
+	Hash   string          `json:"hash"`
+	Record json.RawMessage `json:"record"`
+}
+
+func auditHash(prev string, seq uint64, record []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prev))
+	h.Write([]byte{'\n'})
+	h.Write(strconv.AppendUint(nil, seq, 10))
+	h.Write([]byte{'\n'})
+	h.Write(record)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AuditLogger is an append-only audit logger separated from the default
+// logger, which writes the records into its own rotating file.
+//
+// Each record is synced to the disk before Log returns, and contains
+// the hash of the previous record, so the modification or truncation
+// of the audit file can be detected by VerifyAuditFile.
+type AuditLogger struct {
+	handler slog.Handler
+	writer  *auditWriter
+}
+
+// NewAuditLogger returns a new audit logger writing the records into filename,
+// which is rotated based on filesize and filenum like NewFileWriter.
+//
+// If filename or its last backup has existed, it will be verified
+// and the hash chain continues from its last record.
+func NewAuditLogger(filename, filesize string, filenum int) (*AuditLogger, error) {
+	seq, hash, err := lastAuditHead(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := NewFileWriter(filename, filesize, filenum)
+	if err != nil {
+		return nil, err
+	}
+
+	aw := &auditWriter{file: w, seq: seq, hash: hash}
+	handler := slog.NewJSONHandler(aw, &slog.HandlerOptions{
+		Level:       slog.Level(math.MinInt), // Never drop any record.
+		AddSource:   true,
+		ReplaceAttr: replaceSourceAttr,
+	})
+	return &AuditLogger{handler: handler, writer: aw}, nil
+}
+
+func lastAuditHead(filename string) (seq uint64, hash string, err error) {
+	for _, name := range []string{filename, filename + ".1"} {
+		seq, hash, err = VerifyAuditFile(name, AuditAnyPrevHash)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			err = nil
+		case err != nil:
+			return 0, "", fmt.Errorf("fail to verify the audit file '%s': %w", name, err)
+		case seq > 0:
+			return
+		}
+	}
+	return
+}
+
+// Log emits an audit record with the message and the attributes,
+// which returns an error if failing to write or sync the record.
+//
+// Unlike slog.Logger, the record is always emitted regardless of the level.
+func (l *AuditLogger) Log(ctx context.Context, msg string, args ...any) error {
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, msg, pcs[0])
+	r.Add(args...)
+	r.AddAttrs(slog.String("app", app.Name()))
+	return l.handler.Handle(ctx, r)
+}
+
+// Head returns the sequence and hash of the last written record.
+//
+// It can be compared with the result of VerifyAuditFile
+// to detect the truncation of the tail of the audit file.
+func (l *AuditLogger) Head() (seq uint64, hash string) {
+	l.writer.lock.Lock()
+	defer l.writer.lock.Unlock()
+	return l.writer.seq, l.writer.hash
+}
+
+// Close closes the audit file.
+func (l *AuditLogger) Close() error {
+	l.writer.lock.Lock()
+	defer l.writer.lock.Unlock()
+	return l.writer.file.Close()
+}
+
+// AuditAnyPrevHash is passed to VerifyAuditFile as the previous hash
+// to accept the audit file continuing the chain of the unavailable files.
+const AuditAnyPrevHash = "*"
+
+type auditWriter struct {
+	lock sync.Mutex
+	file io.WriteCloser
+	seq  uint64
+	hash string
+	buf  []byte
+}
+
+func (w *auditWriter) Write(p []byte) (n int, err error) {
+	record := bytes.TrimRight(p, "\n")
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	seq := w.seq + 1
+	hash := auditHash(w.hash, seq, record)
+
+	w.buf = append(w.buf[:0], `{"seq":`...)
+	w.buf = strconv.AppendUint(w.buf, seq, 10)
+	w.buf = append(w.buf, `,"prev":"`...)
+	w.buf = append(w.buf, w.hash...)
+	w.buf = append(w.buf, `","hash":"`...)
+	w.buf = append(w.buf, hash...)
+	w.buf = append(w.buf, `","record":`...)
+	w.buf = append(w.buf, record...)
+	w.buf = append(w.buf, "}\n"...)
+
+	if _, err = w.file.Write(w.buf); err != nil {
+		return
+	}
+
+	// The record has been written, so the chain must continue from it
+	// even if failing to sync it.
+	w.seq, w.hash = seq, hash
+	if s, ok := w.file.(interface{ Sync() error }); ok {
+		if err = s.Sync(); err != nil {
+			return
+		}
+	}
+
+	return len(p), nil
+}
+
+// VerifyAuditFile verifies the hash chain of the audit file written by
+// AuditLogger, and returns the sequence and hash of the last record.
+//
+// If prevhash is empty, the first record must be the first one of the chain,
+// so the removal of the leading records is detected. Or, the first record
+// must be chained to prevhash, which is the hash of the last record
+// of the previous rotated file. If the previous files have been removed,
+// such as by the rotation, pass AuditAnyPrevHash to accept any first record.
+//
+// It returns an error wrapping ErrAuditChainBroken if any record has been
+// modified, removed, inserted or partially truncated.
+func VerifyAuditFile(filename, prevhash string) (seq uint64, hash string, err error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for lineno := 1; ; lineno++ {
+		line, rerr := reader.ReadBytes('\n')
+		if len(line) == 0 && rerr == io.EOF {
+			return
+		} else if rerr != nil && rerr != io.EOF {
+			return 0, "", rerr
+		} else if rerr == io.EOF {
+			err = fmt.Errorf("%w: line %d is truncated", ErrAuditChainBroken, lineno)
+			return
+		}
+
+		var entry auditEntry
+		if err = json.Unmarshal(line, &entry); err != nil {
+			err = fmt.Errorf("%w: line %d is invalid: %v", ErrAuditChainBroken, lineno, err)
+			return
+		}
+
+		switch {
+		case lineno > 1:
+			if entry.Seq != seq+1 || entry.Prev != hash {
+				err = fmt.Errorf("%w: line %d is not chained to the previous",
+					ErrAuditChainBroken, lineno)
+				return
+			}
+
+		case entry.Seq == 1:
+			if entry.Prev != "" {
+				err = fmt.Errorf("%w: the first record has the previous hash", ErrAuditChainBroken)
+				return
+			} else if prevhash != "" && prevhash != AuditAnyPrevHash {
+				err = fmt.Errorf("%w: the first record is not chained to the previous file",
+					ErrAuditChainBroken)
+				return
+			}
+
+		case prevhash == "":
+			err = fmt.Errorf("%w: the leading records before seq %d are missing",
+				ErrAuditChainBroken, entry.Seq)
+			return
+
+		case prevhash != AuditAnyPrevHash && entry.Prev != prevhash:
+			err = fmt.Errorf("%w: the first record is not chained to the previous file",
+				ErrAuditChainBroken)
+			return
+		}
+
+		if entry.Hash != auditHash(entry.Prev, entry.Seq, entry.Record) {
+			err = fmt.Errorf("%w: line %d has been modified", ErrAuditChainBroken, lineno)
+			return
+		}
+
+		seq, hash = entry.Seq, entry.Hash
+	}
+}
+
+// VerifyAuditFiles verifies the hash chain across the rotated audit files,
+// which must be given from the oldest to the newest, such as
+// "audit.log.3", "audit.log.2", "audit.log.1", "audit.log".
+//
+// The oldest file must start the chain. If the older files have been
+// removed by the rotation, verify the oldest one given by VerifyAuditFile
+// with AuditAnyPrevHash, then the rest by chaining the returned hash.
+func VerifyAuditFiles(filenames ...string) error {
+	var hash string
+	for _, filename := range filenames {
+		seq, last, err := VerifyAuditFile(filename, hash)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		} else if seq > 0 {
+			hash = last
+		}
+	}
+	return nil
+}
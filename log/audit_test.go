@@ -0,0 +1,97 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditLogger(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.log")
+
+	logger, err := NewAuditLogger(filename, "1M", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := logger.Log(context.Background(), "audit", "index", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger.Close()
+
+	// Reopen and continue the chain.
+	logger, err = NewAuditLogger(filename, "1M", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Log(context.Background(), "audit", "index", 3); err != nil {
+		t.Fatal(err)
+	}
+	headseq, headhash := logger.Head()
+	logger.Close()
+
+	seq, hash, err := VerifyAuditFile(filename, "")
+	if err != nil {
+		t.Fatal(err)
+	} else if seq != 4 || seq != headseq || hash != headhash {
+		t.Errorf("expect head %d/%s, but got %d/%s", headseq, headhash, seq, hash)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modified := bytes.Replace(data, []byte(`"index":2`), []byte(`"index":9`), 1)
+	if err := os.WriteFile(filename, modified, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyAuditFile(filename, ""); !errors.Is(err, ErrAuditChainBroken) {
+		t.Errorf("expect a broken chain for the modification, but got %v", err)
+	}
+
+	if err := os.WriteFile(filename, data[:len(data)-5], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyAuditFile(filename, ""); !errors.Is(err, ErrAuditChainBroken) {
+		t.Errorf("expect a broken chain for the truncation, but got %v", err)
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	leading := bytes.Join(lines[2:], nil)
+	if err := os.WriteFile(filename, leading, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyAuditFile(filename, ""); !errors.Is(err, ErrAuditChainBroken) {
+		t.Errorf("expect a broken chain for the leading removal, but got %v", err)
+	}
+	if _, _, err := VerifyAuditFile(filename, AuditAnyPrevHash); err != nil {
+		t.Errorf("expect the continuation to be accepted, but got %v", err)
+	}
+
+	removed := bytes.Join(append(lines[:1:1], lines[2:]...), nil)
+	if err := os.WriteFile(filename, removed, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyAuditFile(filename, ""); !errors.Is(err, ErrAuditChainBroken) {
+		t.Errorf("expect a broken chain for the removal, but got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+const compressExt = ".gz"
+
+// backupExts is the extensions of the backup files, which is compressed or not.
+var backupExts = []string{"", compressExt}
+
+// SetCompress sets whether to compress the finished file segments by gzip,
+// which should be set before writing any data.
+//
+// The finished segment is compressed into "path.gz" in the goroutine
+// calling the rotation callbacks, then the original is removed and
+// the callbacks are called with the compressed path.
+func (f *SizedRotatingFile) SetCompress(compress bool) { f.compress = compress }
+
+func (f *SizedRotatingFile) compressSegment(event *rotateEvent) {
+	if !event.snapshot {
+		if path, err := compressFile(event.NewPath, f.filemode); err == nil {
+			event.NewPath = path
+		}
+		return
+	}
+
+	info, err := os.Stat(event.NewPath)
+	if err != nil {
+		return
+	}
+
+	path, err := compressFile(event.NewPath, f.filemode)
+	if err != nil {
+		return
+	}
+	event.NewPath = path
+
+	// The backup may have been shifted while compressing it,
+	// so find it by the file identity and replace it.
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i := 1; i <= f.backupCount; i++ {
+		backup := fmt.Sprintf("%s.%d", f.filename, i)
+		if binfo, err := os.Stat(backup); err == nil && os.SameFile(info, binfo) {
+			os.Remove(backup + compressExt)
+			if os.Link(path, backup+compressExt) == nil {
+				os.Remove(backup)
+			}
+			return
+		}
+	}
+}
+
+// compressFile compresses the file into path+".gz", and removes the original.
+func compressFile(path string, mode os.FileMode) (gzpath string, err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	gzpath = path + compressExt
+	tmppath := gzpath + ".tmp"
+	dst, err := os.OpenFile(tmppath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return
+	}
+
+	gw := gzip.NewWriter(dst)
+	if _, err = io.Copy(gw, src); err == nil {
+		if err = gw.Close(); err == nil {
+			err = dst.Sync()
+		}
+	}
+
+	if _err := dst.Close(); err == nil {
+		err = _err
+	}
+
+	if err == nil {
+		err = os.Rename(tmppath, gzpath)
+	}
+
+	if err != nil {
+		os.Remove(tmppath)
+		return
+	}
+
+	err = os.Remove(path)
+	return
+}
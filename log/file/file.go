@@ -20,8 +20,13 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // ParseSize parses the size string. The size maybe have a unit suffix,
@@ -87,7 +92,7 @@ func ParseSize(s string) (size int64, err error) {
 	return
 }
 
-// NewSizedRotatingFile returns a new SizedRotatingFile, which is not thread-safe.
+// NewSizedRotatingFile returns a new SizedRotatingFile, which is thread-safe.
 //
 // Default:
 //
@@ -115,8 +120,47 @@ func NewSizedRotatingFile(filename string, filesize, filenum int,
 	}
 }
 
+var errClosed = errors.New("the file has been closed")
+
+// Naming is the naming mode of the rotated file segments.
+type Naming int
+
+const (
+	// NamingIndex writes the data into the file named filename, and names
+	// the backups as "filename.1", "filename.2", ..., "filename.N",
+	// which are shifted on each rotation. It is the default mode.
+	NamingIndex Naming = iota
+
+	// NamingTimestamp writes the data into the file named with the creation
+	// timestamp in UTC, such as "filename.20060102T150405.000000", which is
+	// never renamed after created. So the newest one is the active file.
+	NamingTimestamp
+)
+
+const timestampLayout = "20060102T150405.000000"
+
+// RotateEvent is the event emitted after a file segment is rotated.
+//
+// For NamingIndex, since the backups are shifted by the later rotations,
+// NewPath is a hidden hard link to the finished segment in the same
+// directory, such as ".filename.1700000000000000000", which is stable
+// while handling the event and removed after all the callbacks return.
+// If failing to create the link, it is the backup path "filename.1".
+type RotateEvent struct {
+	OldPath string    // The path of the finished segment before rotating.
+	NewPath string    // The path of the finished segment after rotating.
+	Size    int64     // The size of the finished segment.
+	Time    time.Time // The time when rotating.
+}
+
+type rotateEvent struct {
+	RotateEvent
+	snapshot bool // Whether NewPath is the hard link of the segment.
+}
+
 // SizedRotatingFile is a file rotating logging writer based on the size.
 type SizedRotatingFile struct {
+	lock        sync.Mutex
 	file        *os.File
 	filemode    os.FileMode
 	filename    string
@@ -124,12 +168,155 @@ type SizedRotatingFile struct {
 	backupCount int
 	nbytes      int
 	closed      int32
+	onrotate    []func(RotateEvent)
+	naming      Naming
+	symlink     string
+	path        string // The path of the active file
+	policy      SyncPolicy
+	unsynced    int
+	timer       *time.Timer
+	compress    bool
+	notify      chan struct{}
+	eventlock   sync.Mutex
+	events      []rotateEvent
+	pending     sync.WaitGroup // The rotate events not handled yet
+}
+
+// SetNaming resets the naming mode of the file segments,
+// which should be set before writing any data.
+func (f *SizedRotatingFile) SetNaming(naming Naming) { f.naming = naming }
+
+// SetSymlink sets the symbolic link pointing to the active file,
+// which is updated when opening a new file segment.
+//
+// It is useful with NamingTimestamp, and the link name may be filename
+// itself, so that the active file can be always accessed by filename.
+//
+// It should be set before writing any data, and the update of the link
+// is best-effort, which does not hinder the writes when failing.
+// And it never replaces the existing file which is not a symbolic link,
+// such as the active file left by NamingIndex.
+func (f *SizedRotatingFile) SetSymlink(linkname string) { f.symlink = linkname }
+
+// OnRotate appends the callbacks called after a file segment is rotated,
+// which should be set before writing any data.
+//
+// The callbacks are called in turn in a separate goroutine in the order
+// of the rotations, and neither the writes nor the later rotations wait
+// for them, so the callbacks may write the data into the file itself.
+// See RotateEvent about the path of the finished segment.
+func (f *SizedRotatingFile) OnRotate(callbacks ...func(RotateEvent)) {
+	f.onrotate = append(f.onrotate, callbacks...)
+}
+
+func (f *SizedRotatingFile) emitRotateEvent(event RotateEvent, snapshot bool) {
+	if !f.compress && len(f.onrotate) == 0 {
+		return
+	}
+
+	if snapshot {
+		link := filepath.Join(filepath.Dir(f.filename), fmt.Sprintf(".%s.%d",
+			filepath.Base(f.filename), event.Time.UnixNano()))
+		if snapshot = os.Link(event.NewPath, link) == nil; snapshot {
+			event.NewPath = link
+		}
+	}
+
+	if f.notify == nil {
+		f.notify = make(chan struct{}, 1)
+		go f.dispatchRotateEvents(f.notify)
+	}
+
+	// Queue the event without blocking, since the callbacks may write
+	// the data into the file and rotate it again.
+	f.pending.Add(1)
+	f.eventlock.Lock()
+	f.events = append(f.events, rotateEvent{RotateEvent: event, snapshot: snapshot})
+	f.eventlock.Unlock()
+
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (f *SizedRotatingFile) dispatchRotateEvents(notify <-chan struct{}) {
+	for range notify {
+		for {
+			f.eventlock.Lock()
+			if len(f.events) == 0 {
+				f.eventlock.Unlock()
+				break
+			}
+			event := f.events[0]
+			f.events = f.events[1:]
+			f.eventlock.Unlock()
+
+			f.handleRotateEvent(event)
+			f.pending.Done()
+		}
+	}
+}
+
+func (f *SizedRotatingFile) handleRotateEvent(event rotateEvent) {
+	if f.compress {
+		f.compressSegment(&event)
+	}
+
+	for _, cb := range f.onrotate {
+		cb(event.RotateEvent)
+	}
+
+	if event.snapshot {
+		os.Remove(event.NewPath)
+	}
 }
 
-// Close implements io.Closer.
+// Close implements io.Closer, which syncs the data to the disk
+// before closing the file.
+//
+// It also waits for the finished segments to be compressed
+// and the rotation callbacks to finish.
 func (f *SizedRotatingFile) Close() (err error) {
 	if atomic.CompareAndSwapInt32(&f.closed, 0, 1) {
-		err = f.close()
+		err = f.closeFile()
+		f.pending.Wait()
+	}
+	return
+}
+
+func (f *SizedRotatingFile) closeFile() (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+
+	err = f.sync()
+	if _err := f.close(); err == nil {
+		err = _err
+	}
+
+	if f.notify != nil {
+		close(f.notify)
+		f.notify = nil
+	}
+	return
+}
+
+// Reopen closes and reopens the current file, which is used to cooperate
+// with the external tools rotating the file, such as logrotate.
+func (f *SizedRotatingFile) Reopen() (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if atomic.LoadInt32(&f.closed) == 1 {
+		return errClosed
+	}
+
+	if err = f.close(); err == nil {
+		err = f.open()
 	}
 	return
 }
@@ -141,8 +328,12 @@ func (f *SizedRotatingFile) Sync() (err error) {
 
 // Flush flushes the data to the underlying disk.
 func (f *SizedRotatingFile) Flush() (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
 	if f.file != nil {
-		err = f.file.Sync()
+		if err = f.file.Sync(); err == nil {
+			f.unsynced = 0
+		}
 	}
 	return
 }
@@ -150,7 +341,15 @@ func (f *SizedRotatingFile) Flush() (err error) {
 // Write implements io.Writer.
 func (f *SizedRotatingFile) Write(data []byte) (n int, err error) {
 	if atomic.LoadInt32(&f.closed) == 1 {
-		return 0, errors.New("the file has been closed")
+		return 0, errClosed
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// Check it again since it may be closed while waiting for the lock.
+	if atomic.LoadInt32(&f.closed) == 1 {
+		return 0, errClosed
 	}
 
 	if f.file == nil {
@@ -165,16 +364,30 @@ func (f *SizedRotatingFile) Write(data []byte) (n int, err error) {
 		}
 	}
 
-	if n, err = f.file.Write(data); err != nil {
+	n, err = f.file.Write(data)
+	f.nbytes += n
+	if err != nil {
 		return
 	}
 
-	f.nbytes += n
+	err = f.syncAfterWrite(n)
 	return
 }
 
 func (f *SizedRotatingFile) open() (err error) {
-	file, err := os.OpenFile(f.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, f.filemode)
+	if f.path == "" {
+		switch f.naming {
+		case NamingTimestamp:
+			f.path = f.lastSegment()
+			if f.path == "" {
+				f.path = f.newSegment()
+			}
+		default:
+			f.path = f.filename
+		}
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, f.filemode)
 	if err != nil {
 		return
 	}
@@ -187,11 +400,94 @@ func (f *SizedRotatingFile) open() (err error) {
 
 	f.nbytes = int(info.Size())
 	f.file = file
+	f.updateSymlink()
+	return
+}
+
+func (f *SizedRotatingFile) updateSymlink() {
+	if f.symlink == "" || f.symlink == f.path {
+		return
+	}
+
+	target := f.path
+	if filepath.Dir(target) == filepath.Dir(f.symlink) {
+		target = filepath.Base(target)
+	}
+
+	if info, err := os.Lstat(f.symlink); err == nil {
+		if info.Mode()&os.ModeSymlink == 0 {
+			return
+		} else if dst, err := os.Readlink(f.symlink); err == nil && dst == target {
+			return
+		}
+	}
+
+	// Replace the link atomically.
+	tmp := f.symlink + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err == nil {
+		if err = os.Rename(tmp, f.symlink); err != nil {
+			_ = os.Remove(tmp)
+		}
+	}
+}
+
+// segments returns the paths of all the timestamped file segments,
+// which are sorted from the oldest to the newest.
+func (f *SizedRotatingFile) segments() (paths []string) {
+	dir, prefix := filepath.Dir(f.filename), filepath.Base(f.filename)+"."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressExt)
+		if index := strings.IndexByte(ts, '_'); index > -1 {
+			ts = ts[:index]
+		}
+		if _, err := time.Parse(timestampLayout, ts); err == nil {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+
+	sort.Strings(paths)
 	return
 }
 
+// lastSegment returns the newest timestamped file segment if it is not full.
+func (f *SizedRotatingFile) lastSegment() string {
+	if paths := f.segments(); len(paths) > 0 {
+		last := paths[len(paths)-1]
+		if strings.HasSuffix(last, compressExt) {
+			return ""
+		} else if size, err := fileSize(last); err == nil && size < int64(f.maxSize) {
+			return last
+		}
+	}
+	return ""
+}
+
+func (f *SizedRotatingFile) newSegment() string {
+	// Use UTC to keep the order of the names when the local clock falls back.
+	path := f.filename + "." + time.Now().UTC().Format(timestampLayout)
+	for i, base := 1, path; fileIsExist(path) || fileIsExist(path+compressExt); i++ {
+		path = fmt.Sprintf("%s_%d", base, i)
+	}
+	return path
+}
+
 func (f *SizedRotatingFile) close() (err error) {
 	if f.file != nil {
+		if f.policy.Mode != SyncNever {
+			_ = f.sync()
+		}
+		f.unsynced = 0
 		err = f.file.Close()
 		f.file = nil
 	}
@@ -199,6 +495,10 @@ func (f *SizedRotatingFile) close() (err error) {
 }
 
 func (f *SizedRotatingFile) doRollover() (err error) {
+	if f.naming == NamingTimestamp {
+		return f.doTimestampRollover()
+	}
+
 	if f.backupCount > 0 {
 		if err = f.close(); err != nil {
 			return fmt.Errorf("failed to close the rotating file '%s': %s", f.filename, err)
@@ -206,31 +506,38 @@ func (f *SizedRotatingFile) doRollover() (err error) {
 
 		if !fileIsExist(f.filename) {
 			return nil
-		} else if n, err := fileSize(f.filename); err != nil {
+		}
+
+		var size int64
+		if size, err = fileSize(f.filename); err != nil {
 			return fmt.Errorf("failed to get the size of the rotating file '%s': %s",
 				f.filename, err)
-		} else if n == 0 {
+		} else if size == 0 {
 			return nil
 		}
 
 		for _, i := range ranges(f.backupCount-1, 0, -1) {
-			sfn := fmt.Sprintf("%s.%d", f.filename, i)
-			dfn := fmt.Sprintf("%s.%d", f.filename, i+1)
-			if fileIsExist(sfn) {
-				if fileIsExist(dfn) {
-					os.Remove(dfn)
-				}
-				if err = os.Rename(sfn, dfn); err != nil {
-					return fmt.Errorf("failed to rename the rotating file '%s' to '%s': %s",
-						sfn, dfn, err)
+			for _, ext := range backupExts {
+				sfn := fmt.Sprintf("%s.%d%s", f.filename, i, ext)
+				dfn := fmt.Sprintf("%s.%d%s", f.filename, i+1, ext)
+				if fileIsExist(sfn) {
+					if fileIsExist(dfn) {
+						os.Remove(dfn)
+					}
+					if err = os.Rename(sfn, dfn); err != nil {
+						return fmt.Errorf("failed to rename the rotating file '%s' to '%s': %s",
+							sfn, dfn, err)
+					}
 				}
 			}
 		}
 
 		dfn := f.filename + ".1"
-		if fileIsExist(dfn) {
-			if err = os.Remove(dfn); err != nil {
-				return fmt.Errorf("failed to remove the rotating file '%s': %s", dfn, err)
+		for _, ext := range backupExts {
+			if fileIsExist(dfn + ext) {
+				if err = os.Remove(dfn + ext); err != nil {
+					return fmt.Errorf("failed to remove the rotating file '%s': %s", dfn+ext, err)
+				}
 			}
 		}
 		if fileIsExist(f.filename) {
@@ -240,7 +547,51 @@ func (f *SizedRotatingFile) doRollover() (err error) {
 			}
 		}
 
-		err = f.open()
+		if err = f.open(); err != nil {
+			return err
+		}
+
+		f.emitRotateEvent(RotateEvent{
+			OldPath: f.filename,
+			NewPath: dfn,
+			Size:    size,
+			Time:    time.Now(),
+		}, true)
+	}
+
+	return
+}
+
+func (f *SizedRotatingFile) doTimestampRollover() (err error) {
+	if f.backupCount <= 0 || f.nbytes == 0 {
+		return
+	}
+
+	if err = f.close(); err != nil {
+		return fmt.Errorf("failed to close the rotating file '%s': %s", f.path, err)
+	}
+
+	finished, size := f.path, int64(f.nbytes)
+	f.path = f.newSegment()
+	if err = f.open(); err != nil {
+		return
+	}
+
+	if paths := f.segments(); len(paths) > f.backupCount+1 {
+		for _, path := range paths[:len(paths)-f.backupCount-1] {
+			if path != f.path {
+				os.Remove(path)
+			}
+		}
+	}
+
+	if size > 0 {
+		f.emitRotateEvent(RotateEvent{
+			OldPath: finished,
+			NewPath: finished,
+			Size:    size,
+			Time:    time.Now(),
+		}, false)
 	}
 
 	return
@@ -15,11 +15,15 @@
 package file
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestSizedRotatingFile(t *testing.T) {
@@ -67,6 +71,417 @@ func TestSizedRotatingFile(t *testing.T) {
 	}
 }
 
+func TestSizedRotatingFileOnRotate(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test_rotate.log")
+	events := make(chan RotateEvent, 4)
+	contents := make(chan string, 4)
+
+	file := NewSizedRotatingFile(filename, 15, 3)
+	file.OnRotate(func(event RotateEvent) {
+		data, err := os.ReadFile(event.NewPath)
+		if err != nil {
+			t.Error(err)
+		}
+		contents <- string(data)
+		events <- event
+	})
+	defer file.Close()
+
+	data := []byte("0123456789")
+	for i := 0; i < 3; i++ {
+		if _, err := file.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-events:
+			if event.OldPath != filename {
+				t.Errorf("expect old path '%s', but got '%s'", filename, event.OldPath)
+			}
+			if filepath.Dir(event.NewPath) != dir {
+				t.Errorf("expect new path in '%s', but got '%s'", dir, event.NewPath)
+			}
+			if event.Size != 10 {
+				t.Errorf("expect size %d, but got %d", 10, event.Size)
+			}
+			if content := <-contents; content != string(data) {
+				t.Errorf("expect the segment '%s', but got '%s'", data, content)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout to wait for the rotate event")
+		}
+	}
+
+	// The links of the finished segments are removed after handled.
+	file.pending.Wait()
+	if matches, _ := filepath.Glob(filepath.Join(dir, ".*")); len(matches) > 0 {
+		t.Errorf("unexpected links of the segments: %v", matches)
+	}
+}
+
+func TestSizedRotatingFileOnRotateNoBlock(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test_rotate_noblock.log")
+	release := make(chan struct{})
+
+	file := NewSizedRotatingFile(filename, 15, 3)
+	file.OnRotate(func(RotateEvent) { <-release })
+	defer file.Close()
+
+	// The slow callback must not hold up the writes and the rotations.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			if _, err := file.Write([]byte("0123456789")); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Error("the writes are held up by the rotation callback")
+	}
+	close(release)
+}
+
+func TestSizedRotatingFileOnRotateWrite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test_rotate_write.log")
+
+	file := NewSizedRotatingFile(filename, 100, 3)
+	file.OnRotate(func(event RotateEvent) {
+		// Write the file itself, such as logging the rotation.
+		if _, err := file.Write([]byte("rotated\n")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			if _, err := file.Write([]byte("0123456789")); err != nil {
+				t.Error(err)
+			}
+		}
+		file.pending.Wait()
+		file.Close()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("deadlock when the rotation callback writes the file")
+	}
+}
+
+func TestSizedRotatingFileOnRotateOrder(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test_rotate_order.log")
+	contents := make(chan string, 4)
+
+	file := NewSizedRotatingFile(filename, 15, 3)
+	file.OnRotate(func(event RotateEvent) {
+		// Simulate the slow handler, such as uploading the segment.
+		time.Sleep(time.Millisecond * 50)
+		data, err := os.ReadFile(event.NewPath)
+		if err != nil {
+			t.Error(err)
+		}
+		contents <- string(data)
+	})
+
+	for _, s := range []string{"aaaaaaaaaa", "bbbbbbbbbb", "cccccccccc"} {
+		if _, err := file.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file.Close()
+	file.pending.Wait()
+
+	close(contents)
+	var results []string
+	for content := range contents {
+		results = append(results, content)
+	}
+	if len(results) != 2 || results[0] != "aaaaaaaaaa" || results[1] != "bbbbbbbbbb" {
+		t.Errorf("unexpected segments handled by the callbacks: %v", results)
+	}
+}
+
+func TestSizedRotatingFileCompressShifted(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test_compress_shifted.log")
+	release := make(chan struct{})
+
+	file := NewSizedRotatingFile(filename, 15, 3)
+	file.SetCompress(true)
+	file.OnRotate(func(RotateEvent) { <-release })
+
+	// The backups are shifted while the first is being handled.
+	for _, s := range []string{"aaaaaaaaaa", "bbbbbbbbbb", "cccccccccc"} {
+		if _, err := file.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(release)
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expect := range map[string]string{
+		filename:           "cccccccccc",
+		filename + ".1":    "",
+		filename + ".2":    "",
+		filename + ".1.gz": "bbbbbbbbbb",
+		filename + ".2.gz": "aaaaaaaaaa",
+	} {
+		if expect == "" {
+			if fileIsExist(name) {
+				t.Errorf("unexpected the file '%s'", name)
+			}
+			continue
+		}
+
+		data, err := os.ReadFile(name)
+		if err == nil && strings.HasSuffix(name, compressExt) {
+			data, err = gunzip(data)
+		}
+		if err != nil {
+			t.Error(err)
+		} else if string(data) != expect {
+			t.Errorf("%s: expect '%s', but got '%s'", name, expect, data)
+		}
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(dir, ".*")); len(matches) > 0 {
+		t.Errorf("unexpected links of the segments: %v", matches)
+	}
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestSizedRotatingFileTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test_timestamp.log")
+
+	file := NewSizedRotatingFile(filename, 15, 2)
+	file.SetNaming(NamingTimestamp)
+	file.SetSymlink(filename)
+	defer file.Close()
+
+	data := []byte("0123456789")
+	for i := 0; i < 5; i++ {
+		if _, err := file.Write(data); err != nil {
+			t.Fatal(err)
+		}
+
+		if target, err := os.Readlink(filename); err != nil {
+			t.Fatal(err)
+		} else if path := filepath.Join(dir, target); path != file.path {
+			t.Errorf("expect symlink to '%s', but got '%s'", file.path, path)
+		}
+	}
+
+	segments := file.segments()
+	if len(segments) != 3 {
+		t.Fatalf("expect %d segments, but got %d: %v", 3, len(segments), segments)
+	} else if last := segments[len(segments)-1]; last != file.path {
+		t.Errorf("expect the active file '%s', but got '%s'", last, file.path)
+	}
+
+	for _, segment := range segments {
+		if size, err := fileSize(segment); err != nil {
+			t.Error(err)
+		} else if size != 10 {
+			t.Errorf("expect file size %d, but got %d", 10, size)
+		}
+	}
+}
+
+func TestSizedRotatingFileTimestampUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*3600)
+	defer func() { time.Local = local }()
+
+	filename := filepath.Join(t.TempDir(), "test_timestamp_utc.log")
+	file := NewSizedRotatingFile(filename, 15, 2)
+	file.SetNaming(NamingTimestamp)
+	defer file.Close()
+
+	if _, err := file.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := strings.TrimPrefix(file.path, filename+".")
+	if created, err := time.Parse(timestampLayout, ts); err != nil {
+		t.Fatal(err)
+	} else if d := time.Since(created); d < 0 || d > time.Minute {
+		t.Errorf("expect the segment named by the current time in UTC, but got '%s'", ts)
+	}
+}
+
+func TestSizedRotatingFileSymlinkNotReplace(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test_symlink.log")
+	if err := os.WriteFile(filename, []byte("index"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := NewSizedRotatingFile(filename, 15, 2)
+	file.SetNaming(NamingTimestamp)
+	file.SetSymlink(filename)
+	defer file.Close()
+
+	if _, err := file.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The regular file left by NamingIndex must not be replaced.
+	if data, err := os.ReadFile(filename); err != nil {
+		t.Error(err)
+	} else if string(data) != "index" {
+		t.Errorf("expect the regular file kept, but got '%s'", data)
+	}
+}
+
+func TestSizedRotatingFileCompress(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test_compress.log")
+	events := make(chan RotateEvent, 4)
+
+	file := NewSizedRotatingFile(filename, 15, 2)
+	file.OnRotate(func(event RotateEvent) { events <- event })
+	file.SetCompress(true)
+	defer file.Close()
+
+	data := []byte("0123456789")
+	for i := 0; i < 4; i++ {
+		if _, err := file.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case event := <-events:
+			if !strings.HasSuffix(event.NewPath, ".gz") {
+				t.Errorf("expect a compressed segment, but got '%s'", event.NewPath)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout to wait for the rotate event")
+		}
+	}
+
+	file.pending.Wait()
+	for _, name := range []string{filename, filename + ".1.gz", filename + ".2.gz"} {
+		if !fileIsExist(name) {
+			t.Errorf("expect the file '%s', but not exist", name)
+		}
+	}
+	for _, name := range []string{filename + ".1", filename + ".2", filename + ".3.gz"} {
+		if fileIsExist(name) {
+			t.Errorf("unexpected the file '%s'", name)
+		}
+	}
+}
+
+func TestSizedRotatingFileReopen(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test_reopen.log")
+	file := NewSizedRotatingFile(filename, 1024, 3)
+	defer file.Close()
+
+	if _, err := file.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate the external rotation, such as logrotate.
+	if err := os.Rename(filename, filename+".old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte("xyz")); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(filename + ".old"); err != nil {
+		t.Error(err)
+	} else if string(data) != "abc" {
+		t.Errorf("expect '%s', but got '%s'", "abc", data)
+	}
+
+	if data, err := os.ReadFile(filename); err != nil {
+		t.Error(err)
+	} else if string(data) != "xyz" {
+		t.Errorf("expect '%s', but got '%s'", "xyz", data)
+	}
+}
+
+func TestSizedRotatingFileClose(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test_close.log")
+
+	file := NewSizedRotatingFile(filename, 15, 3)
+	file.SetCompress(true)
+	for _, s := range []string{"aaaaaaaaaa", "bbbbbbbbbb"} {
+		if _, err := file.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Close must wait for the finished segment to be compressed.
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !fileIsExist(filename + ".1.gz") {
+		t.Errorf("expect the compressed segment '%s'", filename+".1.gz")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(matches) > 0 {
+		t.Errorf("unexpected temporary files: %v", matches)
+	}
+
+	if _, err := file.Write([]byte("c")); err == nil {
+		t.Error("expect an error to write the closed file, but got nil")
+	}
+	if err := file.Reopen(); err == nil {
+		t.Error("expect an error to reopen the closed file, but got nil")
+	}
+}
+
+func TestParseSyncPolicy(t *testing.T) {
+	for _, c := range []struct {
+		input  string
+		policy SyncPolicy
+	}{
+		{"", SyncPolicy{Mode: SyncNever}},
+		{"never", SyncPolicy{Mode: SyncNever}},
+		{"always", SyncPolicy{Mode: SyncAlways}},
+		{"1s", SyncPolicy{Mode: SyncInterval, Interval: time.Second}},
+		{"4096", SyncPolicy{Mode: SyncBytes, Bytes: 4096}},
+		{"1K", SyncPolicy{Mode: SyncBytes, Bytes: 1024}},
+	} {
+		if policy, err := ParseSyncPolicy(c.input); err != nil {
+			t.Errorf("%s: %v", c.input, err)
+		} else if policy != c.policy {
+			t.Errorf("%s: expect %+v, but got %+v", c.input, c.policy, policy)
+		}
+	}
+
+	if _, err := ParseSyncPolicy("abc"); err == nil {
+		t.Errorf("expect an error, but got nil")
+	}
+}
+
 func listdir(dir, prefix string) (files map[string]int64) {
 	files = make(map[string]int64)
 	_ = filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
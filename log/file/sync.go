@@ -0,0 +1,120 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SyncMode is the mode when to sync the written data to the underlying disk.
+type SyncMode int
+
+// Predefine some sync modes.
+const (
+	SyncNever    SyncMode = iota // Never sync except closing the file.
+	SyncAlways                   // Sync after every write.
+	SyncBytes                    // Sync after writing every SyncPolicy.Bytes bytes.
+	SyncInterval                 // Sync within SyncPolicy.Interval after writing.
+)
+
+// SyncPolicy is the policy to sync the written data to the underlying disk.
+type SyncPolicy struct {
+	Mode     SyncMode
+	Bytes    int           // Only for SyncBytes
+	Interval time.Duration // Only for SyncInterval
+}
+
+// ParseSyncPolicy parses the sync policy string, which supports
+//
+//	"" or "never": SyncNever
+//	"always":      SyncAlways
+//	a duration:    SyncInterval, such as "100ms", "1s"
+//	a size:        SyncBytes, such as "4096", "1M", see ParseSize
+func ParseSyncPolicy(s string) (policy SyncPolicy, err error) {
+	switch s = strings.TrimSpace(s); s {
+	case "", "never":
+		return
+
+	case "always":
+		policy.Mode = SyncAlways
+		return
+	}
+
+	if interval, _err := time.ParseDuration(s); _err == nil && interval > 0 {
+		policy.Mode = SyncInterval
+		policy.Interval = interval
+		return
+	}
+
+	size, err := ParseSize(s)
+	switch {
+	case err != nil:
+		err = fmt.Errorf("invalid sync policy '%s'", s)
+	case size > 0:
+		policy.Mode = SyncBytes
+		policy.Bytes = int(size)
+	}
+
+	return
+}
+
+// SetSyncPolicy resets the policy to sync the written data to the disk.
+//
+// Whatever the policy is, the data is always synced when closing the file.
+func (f *SizedRotatingFile) SetSyncPolicy(policy SyncPolicy) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.policy = policy
+}
+
+// syncAfterWrite syncs the file after writing n bytes by the policy,
+// which must be called with the lock.
+func (f *SizedRotatingFile) syncAfterWrite(n int) (err error) {
+	f.unsynced += n
+	switch f.policy.Mode {
+	case SyncAlways:
+		err = f.sync()
+
+	case SyncBytes:
+		if f.unsynced >= f.policy.Bytes {
+			err = f.sync()
+		}
+
+	case SyncInterval:
+		if f.timer == nil {
+			f.timer = time.AfterFunc(f.policy.Interval, f.syncByTimer)
+		}
+	}
+	return
+}
+
+func (f *SizedRotatingFile) syncByTimer() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.timer = nil
+	_ = f.sync()
+}
+
+// sync syncs the data of the active file, which must be called with the lock.
+func (f *SizedRotatingFile) sync() (err error) {
+	if f.file != nil && f.unsynced > 0 {
+		if err = f.file.Sync(); err == nil {
+			f.unsynced = 0
+		}
+	}
+	return
+}
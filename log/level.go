@@ -43,14 +43,15 @@ var Level = new(slog.LevelVar)
 // If empty, use slog.LevelInfo instead.
 // If an integer, convert it to slog.Level directly.
 func SetLevel(level string) error {
-	lvl, err := parseLevel(level)
+	lvl, err := ParseLevel(level)
 	if err == nil {
 		Level.Set(lvl)
 	}
 	return err
 }
 
-func parseLevel(lvl string) (level slog.Level, err error) {
+// ParseLevel parses the level string, which is the same as SetLevel.
+func ParseLevel(lvl string) (level slog.Level, err error) {
 	switch strings.ToLower(lvl) {
 	case "":
 		level = slog.LevelInfo
@@ -70,7 +71,7 @@ func parseLevel(lvl string) (level slog.Level, err error) {
 		if v, _err := strconv.ParseInt(lvl, 10, 64); _err == nil {
 			level = slog.Level(v)
 		} else {
-			err = fmt.Errorf("unknown level '%s'", level)
+			err = fmt.Errorf("unknown level '%s'", lvl)
 		}
 	}
 	return
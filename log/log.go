@@ -17,20 +17,32 @@ package log
 
 import (
 	"context"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"log"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/xgfone/go-toolkit/app"
+	"github.com/xgfone/goapp/log/file"
+	"github.com/xgfone/goapp/writer"
 )
 
 func init() {
-	handler := NewOptionHandler(NewJSONHandler(Writer, Level))
+	handler := NewOptionHandler(NewJSONHandler(WriterStats, Level))
 	handler.ReplaceFunc = replaceAttrForAppName
 	SetDefault(handler)
 }
 
+func init() {
+	// Close will sync the data of the log file to the disk finally.
+	app.StageExited.On(func(context.Context, *app.App) error {
+		return closeWriter(Writer.SwapAndWait(os.Stderr))
+	})
+}
+
 func replaceAttrForAppName(c context.Context, r slog.Record) slog.Record {
 	r.AddAttrs(slog.String("app", app.Name()))
 	return r
@@ -46,55 +58,133 @@ func SetDefault(handler slog.Handler, attrs ...slog.Attr) {
 	slog.SetDefault(slog.New(handler))
 }
 
+// FileConfig is the configuration of the log file.
+type FileConfig struct {
+	Name     string      // The file path, or "stdout", "stderr".
+	Size     string      // The maximum size of each file. Default: "100M"
+	Num      int         // The number of the backup files. Default: 100
+	Sync     string      // The fsync policy, see file.ParseSyncPolicy. Default: "never"
+	Mode     os.FileMode // The permission of the log files. Default: 0644
+	DirMode  os.FileMode // The permission of the log directory. Default: 0700
+	Naming   string      // The naming mode, "index" or "timestamp". Default: "index"
+	Compress bool        // Whether to compress the rotated files by gzip.
+	Key      string      // The hex-encoded AES key to encrypt the log files if set, which are flushed every second.
+}
+
 // Init initializes the logging configuration.
 //
 // If file is empty or equal to "stderr", output the log to os.Stderr.
 // If file is equal to "stdout", output the log to os.Stdout.
 // Or, output the log to the given file.
 func Init(level, file string, logfilenum int) (err error) {
+	return InitFile(level, FileConfig{Name: file, Num: logfilenum})
+}
+
+// InitFile is the same as Init, but uses the full file configuration.
+func InitFile(level string, config FileConfig) (err error) {
 	if err = SetLevel(level); err != nil {
 		return
 	}
 
-	switch file {
-	case "":
-		return
+	if config.Name != "" {
+		err = SetFile(config)
+	}
 
-	case "stdout":
-		Writer.Set(os.Stdout)
+	return
+}
 
-	case "stderr":
-		Writer.Set(os.Stderr)
+// SetFile re-creates the log writer by the file configuration,
+// then swaps it with the old writer and closes the old.
+//
+// If config.Name is empty or equal to "stderr", output the log to os.Stderr.
+// If config.Name is equal to "stdout", output the log to os.Stdout.
+func SetFile(config FileConfig) (err error) {
+	var w io.Writer
+	switch config.Name {
+	case "", "stderr":
+		w = os.Stderr
+
+	case "stdout":
+		w = os.Stdout
 
 	default:
-		err = setfilewriter(file, logfilenum)
+		if config.Num <= 0 {
+			config.Num = 100
+		}
+		if config.Size == "" {
+			config.Size = "100M"
+		}
+
+		var _file *file.SizedRotatingFile
+		if _file, err = newFileWriter(config); err != nil {
+			return
+		}
+		w = _file
+
+		if config.Key != "" {
+			var key []byte
+			if key, err = hex.DecodeString(config.Key); err != nil {
+				_file.Close()
+				return fmt.Errorf("invalid log file key: %w", err)
+			}
+
+			// Flush the partial chunk in time to avoid losing the logs.
+			if w, err = writer.NewEncryptWriter(_file, key, 0, time.Second); err != nil {
+				_file.Close()
+				return
+			}
+		}
 	}
 
+	closeWriter(Writer.SwapAndWait(w))
 	return
 }
 
-func setfilewriter(file string, logfilenum int) (err error) {
-	if logfilenum <= 0 {
-		logfilenum = 100
-	}
+// Reopen reopens the current log file, which is used to cooperate
+// with the external tools rotating the log file, such as logrotate.
+//
+// It does nothing if the log is not output to a file.
+func Reopen() error {
+	for w := Writer.Get(); w != nil; {
+		if r, ok := w.(interface{ Reopen() error }); ok {
+			return r.Reopen()
+		}
 
-	_file, err := NewFileWriter(file, "100M", logfilenum)
-	if err != nil {
-		return
+		u, ok := w.(interface{ Unwrap() io.Writer })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
 	}
+	return nil
+}
 
-	app.StageExited.On(func(context.Context, *app.App) error {
-		Writer.Set(os.Stderr)
-		return _file.Close()
-	})
+// Flush flushes the buffered logs of the current log writer and
+// the writers wrapped by it, such as the encrypted log file.
+func Flush() (err error) {
+	for w := Writer.Get(); w != nil; {
+		if f, ok := w.(interface{ Flush() error }); ok {
+			if _err := f.Flush(); err == nil {
+				err = _err
+			}
+		}
 
-	switch old := Writer.Swap(_file); old {
+		u, ok := w.(interface{ Unwrap() io.Writer })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	return
+}
+
+func closeWriter(w io.Writer) (err error) {
+	switch w {
 	case os.Stderr, os.Stdout:
 	default:
-		if c, ok := old.(io.Closer); ok {
-			c.Close()
+		if c, ok := w.(io.Closer); ok {
+			err = c.Close()
 		}
 	}
-
 	return
 }
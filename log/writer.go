@@ -15,7 +15,10 @@
 package log
 
 import (
+	"encoding/hex"
 	"errors"
+	"expvar"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -27,6 +30,14 @@ import (
 // Writer is the default global writer.
 var Writer = writer.NewSwitcher(os.Stderr)
 
+// WriterStats collects the statistics of the writes of the default logger
+// into Writer, which is published by expvar as "logwriter".
+var WriterStats = writer.NewStatsWriter(Writer)
+
+func init() {
+	expvar.Publish("logwriter", expvar.Func(func() any { return WriterStats.Stats() }))
+}
+
 // NewFileWriter returns a new file writer that rotates the files
 // based on the file size, which is used as the log writer.
 //
@@ -35,17 +46,84 @@ var Writer = writer.NewSwitcher(os.Stderr)
 // "m", "M", "g", "G", "t", "T", "p", "P", "e", "E". The lower units are 1000x,
 // and the upper units are 1024x.
 func NewFileWriter(filename, filesize string, filenum int) (io.WriteCloser, error) {
-	if filename == "" {
-		return nil, errors.New("the log filename must not be empty")
+	file, err := newFileWriter(FileConfig{Name: filename, Size: filesize, Num: filenum})
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// CheckFileConfig checks whether the file configuration is valid,
+// without creating the log file.
+func CheckFileConfig(config FileConfig) (err error) {
+	switch config.Name {
+	case "", "stderr", "stdout":
+		return
+	}
+
+	if _, _, _, err = parseFileConfig(config); err != nil {
+		return
 	}
 
-	size, err := file.ParseSize(filesize)
+	if config.Key != "" {
+		key, err := hex.DecodeString(config.Key)
+		if err != nil {
+			return fmt.Errorf("invalid log file key: %w", err)
+		}
+
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return fmt.Errorf("invalid log file key: the key must be 16, 24 or 32 bytes, but got %d", len(key))
+		}
+	}
+
+	return
+}
+
+func parseFileConfig(config FileConfig) (size int64, policy file.SyncPolicy, naming file.Naming, err error) {
+	if config.Name == "" {
+		err = errors.New("the log filename must not be empty")
+		return
+	}
+
+	if size, err = file.ParseSize(config.Size); err != nil {
+		return
+	}
+
+	if policy, err = file.ParseSyncPolicy(config.Sync); err != nil {
+		return
+	}
+
+	switch config.Naming {
+	case "", "index":
+		naming = file.NamingIndex
+	case "timestamp":
+		naming = file.NamingTimestamp
+	default:
+		err = fmt.Errorf("unknown log file naming '%s'", config.Naming)
+	}
+	return
+}
+
+func newFileWriter(config FileConfig) (*file.SizedRotatingFile, error) {
+	size, policy, naming, err := parseFileConfig(config)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+	dirmode := config.DirMode
+	if dirmode == 0 {
+		dirmode = 0700
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.Name), dirmode); err != nil {
 		return nil, err
 	}
-	return file.NewSizedRotatingFile(filename, int(size), filenum), nil
+
+	_file := file.NewSizedRotatingFile(config.Name, int(size), config.Num, config.Mode)
+	_file.SetSyncPolicy(policy)
+	_file.SetNaming(naming)
+	_file.SetCompress(config.Compress)
+	return _file, nil
 }
@@ -0,0 +1,92 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package systemd provides the notification to systemd,
+// which is the same as sd_notify.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Predefine some notification states.
+const (
+	Ready     = "READY=1"
+	Stopping  = "STOPPING=1"
+	Reloading = "RELOADING=1"
+	Watchdog  = "WATCHDOG=1"
+)
+
+// Notify sends the state to systemd by the unix datagram socket
+// specified by the environment variable NOTIFY_SOCKET.
+//
+// If NOTIFY_SOCKET is not set, it does nothing and returns false.
+// The multiple states can be joined by newline.
+func Notify(state string) (sent bool, err error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+
+	// The abstract socket address starts with '@'.
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err = conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Status sends the free-form status message to systemd,
+// which is shown by "systemctl status".
+func Status(status string) (sent bool, err error) {
+	return Notify("STATUS=" + status)
+}
+
+// WatchdogInterval returns the watchdog timeout specified by
+// the environment variable WATCHDOG_USEC.
+//
+// If WATCHDOG_USEC is not set, or WATCHDOG_PID is set but is not
+// the current process, return 0. The service should send Watchdog
+// to systemd regularly within the timeout, such as a half of it.
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" {
+		if pid != strconv.Itoa(os.Getpid()) {
+			return 0, nil
+		}
+	}
+
+	v, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC '%s'", usec)
+	}
+	return time.Duration(v) * time.Microsecond, nil
+}
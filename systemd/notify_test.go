@@ -0,0 +1,84 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := Notify(Ready); err != nil || sent {
+		t.Fatalf("expect not to send, but got sent=%v, err=%v", sent, err)
+	}
+
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", socket)
+	for _, state := range []string{Ready, "STATUS=running", Stopping} {
+		var sent bool
+		if state == "STATUS=running" {
+			sent, err = Status("running")
+		} else {
+			sent, err = Notify(state)
+		}
+		if err != nil || !sent {
+			t.Fatalf("expect to send, but got sent=%v, err=%v", sent, err)
+		}
+
+		buf := make([]byte, 64)
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		} else if msg := string(buf[:n]); msg != state {
+			t.Errorf("expect '%s', but got '%s'", state, msg)
+		}
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	if interval, err := WatchdogInterval(); err != nil || interval != 0 {
+		t.Errorf("expect 0, but got %s, %v", interval, err)
+	}
+
+	t.Setenv("WATCHDOG_USEC", "3000000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	if interval, err := WatchdogInterval(); err != nil || interval != time.Second*3 {
+		t.Errorf("expect 3s, but got %s, %v", interval, err)
+	}
+
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	if interval, err := WatchdogInterval(); err != nil || interval != 0 {
+		t.Errorf("expect 0, but got %s, %v", interval, err)
+	}
+
+	t.Setenv("WATCHDOG_PID", "")
+	t.Setenv("WATCHDOG_USEC", "abc")
+	if _, err := WatchdogInterval(); err == nil {
+		t.Error("expect an error, but got nil")
+	}
+}
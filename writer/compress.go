@@ -0,0 +1,180 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+	"time"
+)
+
+// Compressor returns a new compressing writer writing into w, which must
+// produce an independently decodable block, such as a gzip member
+// or a zstd frame, after it is closed.
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+// GzipCompressor is a compressor based on gzip.
+//
+// The concatenated gzip members are still a valid gzip stream,
+// which can be decoded by gzip.Reader directly.
+func GzipCompressor(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+// CompressWriter is a writer proxy, which buffers and compresses the data
+// into the wrapped writer block by block.
+//
+// Each flush compresses the buffered data into an independently decodable
+// block and writes it into the wrapped writer by one Write call, so the
+// partially written stream is still readable except the last block
+// after a crash.
+type CompressWriter struct {
+	writer     io.Writer
+	compressor Compressor
+	flushsize  int
+	interval   time.Duration
+
+	lock  sync.Mutex
+	data  bytes.Buffer // The uncompressed data
+	block bytes.Buffer // The compressed block
+	timer *time.Timer
+	err   error // The error of the last flush by the timer.
+}
+
+// maxCompressBuffer is the maximum size of the buffered data
+// if the flush size is not set.
+const maxCompressBuffer = 1024 * 1024
+
+// NewCompressWriter returns a new CompressWriter wrapping w.
+//
+// If compressor is nil, use GzipCompressor instead.
+// If flushSize is positive, flush the data after buffering flushSize bytes.
+// If flushInterval is positive, flush the data within flushInterval
+// after buffering it.
+//
+// The buffered data is limited to flushSize, or 1MiB if flushSize is not
+// positive. Write flushes the buffered data first if exceeding the limit,
+// and returns 0 and the error without buffering the data if failing.
+func NewCompressWriter(w io.Writer, compressor Compressor, flushSize int,
+	flushInterval time.Duration) *CompressWriter {
+	if w == nil {
+		panic("NewCompressWriter: io.Writer is nil")
+	}
+	if compressor == nil {
+		compressor = GzipCompressor
+	}
+
+	return &CompressWriter{
+		writer:     w,
+		compressor: compressor,
+		flushsize:  flushSize,
+		interval:   flushInterval,
+	}
+}
+
+// Unwrap returns the wrapped writer.
+func (w *CompressWriter) Unwrap() io.Writer { return w.writer }
+
+// Write implements the interface io.Writer.
+func (w *CompressWriter) Write(p []byte) (n int, err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err, w.err = w.err, nil; err != nil {
+		return
+	}
+
+	limit := w.flushsize
+	if limit <= 0 {
+		limit = maxCompressBuffer
+	}
+	if w.data.Len() > 0 && w.data.Len()+len(p) > limit {
+		if err = w.flush(); err != nil {
+			return
+		}
+	}
+
+	n, _ = w.data.Write(p)
+	if w.flushsize > 0 && w.data.Len() >= w.flushsize {
+		// p has been buffered, so the error is returned
+		// by the next Write or Flush flushing it again.
+		_ = w.flush()
+	} else if w.interval > 0 && w.timer == nil {
+		w.timer = time.AfterFunc(w.interval, w.flushByTimer)
+	}
+	return
+}
+
+func (w *CompressWriter) flushByTimer() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.timer = nil
+	if err := w.flush(); err != nil {
+		w.err = err
+	}
+}
+
+// Flush compresses the buffered data into a block
+// and writes it into the wrapped writer.
+func (w *CompressWriter) Flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.flush()
+}
+
+func (w *CompressWriter) flush() (err error) {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+
+	if w.data.Len() == 0 {
+		return
+	}
+
+	w.block.Reset()
+	cw, err := w.compressor(&w.block)
+	if err != nil {
+		return
+	}
+
+	if _, err = cw.Write(w.data.Bytes()); err != nil {
+		cw.Close()
+		return
+	}
+	if err = cw.Close(); err != nil {
+		return
+	}
+
+	if _, err = w.writer.Write(w.block.Bytes()); err == nil {
+		w.data.Reset()
+	}
+	return
+}
+
+// Close flushes the buffered data and closes the wrapped writer
+// if it implements io.Closer.
+func (w *CompressWriter) Close() (err error) {
+	if err = w.Flush(); err != nil {
+		return
+	}
+
+	if c, ok := w.writer.(io.Closer); ok {
+		err = c.Close()
+	}
+	return
+}
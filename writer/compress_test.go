@@ -0,0 +1,95 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestCompressWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewCompressWriter(buf, nil, 8, 0)
+
+	for _, s := range []string{"abc", "defgh", "ijk", "lmn"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first block has been flushed, and the crash is simulated
+	// by appending the partial data of the second block.
+	complete := buf.Len()
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	partial := buf.Bytes()[:complete+(buf.Len()-complete)/2]
+
+	r, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := io.ReadAll(r); err != nil {
+		t.Fatal(err)
+	} else if s := string(data); s != "abcdefghijklmn" {
+		t.Errorf("expect '%s', but got '%s'", "abcdefghijklmn", s)
+	}
+
+	r, err = gzip.NewReader(bytes.NewReader(partial))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(r); string(data) != "abcdefgh" {
+		t.Errorf("expect '%s', but got '%s'", "abcdefgh", string(data))
+	}
+}
+
+func TestCompressWriterRetry(t *testing.T) {
+	buf := &toggleWriter{fail: true}
+	w := NewCompressWriter(buf, nil, 8, 0)
+
+	// The data is buffered, though it fails to be flushed.
+	if n, err := w.Write([]byte("abcdefgh")); n != 8 || err != nil {
+		t.Errorf("expect to buffer %d bytes, but got %d, %v", 8, n, err)
+	}
+
+	// Nothing is buffered since the buffer is full.
+	if n, err := w.Write([]byte("ijk")); n != 0 || err == nil {
+		t.Errorf("expect to fail to write without any data, but got %d, %v", n, err)
+	}
+	if size := w.data.Len(); size != 8 {
+		t.Errorf("expect %d bytes buffered, but got %d", 8, size)
+	}
+
+	buf.fail = false
+	if _, err := w.Write([]byte("ijk")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := io.ReadAll(r); err != nil {
+		t.Fatal(err)
+	} else if s := string(data); s != "abcdefghijk" {
+		t.Errorf("expect '%s', but got '%s'", "abcdefghijk", s)
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultChunkSize is the default size of the plaintext of each chunk.
+const DefaultChunkSize = 4 * 1024
+
+// ErrInvalidChunk is returned by Decrypt when the encrypted chunk is invalid,
+// such as being modified, truncated, reordered or encrypted by another key.
+var ErrInvalidChunk = errors.New("invalid encrypted chunk")
+
+// The format of each chunk:
+//
+//	| length (4 bytes) | run (8 bytes) | sequence (8 bytes) | sealed data (length bytes) |
+//
+// The run is a random id of each EncryptWriter, and the sequence starts
+// from 0 in each run. Both are authenticated as the additional data.
+//
+// The data is sealed by the subkey derived from the key and the run
+// by HKDF-SHA256, and the nonce is the sequence. So the nonces are never
+// reused under the same subkey without the limit of the random nonces.
+//
+// The plaintext of the sealed data is the data length (4 bytes),
+// the data and the zero padding up to the chunk size.
+const (
+	chunkLenSize  = 4
+	chunkRunSize  = 8
+	chunkSeqSize  = 8
+	chunkAADSize  = chunkRunSize + chunkSeqSize
+	chunkHeadSize = chunkLenSize + chunkAADSize
+	plainLenSize  = 4
+)
+
+func checkKey(key []byte) error {
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	default:
+		return aes.KeySizeError(len(key))
+	}
+}
+
+// newRunGCM returns the AES-GCM with the subkey derived from key and run.
+func newRunGCM(key, run []byte) (cipher.AEAD, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	// HKDF-SHA256 with the run as the salt, and the output is not longer
+	// than a hash block, so only one round of the expansion is required.
+	extract := hmac.New(sha256.New, run)
+	extract.Write(key)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte("goapp encrypted chunk\x01"))
+	subkey := expand.Sum(nil)[:len(key)]
+
+	block, err := aes.NewCipher(subkey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(nonce, seq []byte) []byte {
+	clear(nonce[:len(nonce)-chunkSeqSize])
+	copy(nonce[len(nonce)-chunkSeqSize:], seq)
+	return nonce
+}
+
+// EncryptWriter is a writer proxy, which buffers and encrypts the data
+// by AES-GCM into the authenticated chunks with the fixed size, and writes
+// each chunk into the wrapped writer by one Write call.
+//
+// Each chunk can be decrypted independently, so it can be composed with
+// the rotating file, such as file.SizedRotatingFile, which does not split
+// a Write across the files. Use Decrypt to read the data back.
+//
+// The chunks of each EncryptWriter are tagged with a random run id,
+// so the multiple writers, such as after restarting the process,
+// can append the chunks into the same file in turn.
+//
+// Since all the chunks have the same size to hide the sizes of the data,
+// the chunk flushed before full, by Flush, Close or the flush interval,
+// is padded up to the chunk size. So the smaller chunk size wastes less
+// space for the frequent flushes, but more for the overhead of each chunk.
+// And the buffered data is lost if the process crashes before flushing it.
+type EncryptWriter struct {
+	writer   io.Writer
+	aead     cipher.AEAD
+	size     int
+	interval time.Duration
+	run      [chunkRunSize]byte
+
+	lock  sync.Mutex
+	seq   uint64
+	data  []byte // The buffered plaintext with the data length ahead
+	buf   []byte // The encrypted chunk
+	nonce []byte
+	timer *time.Timer
+}
+
+// NewEncryptWriter returns a new EncryptWriter wrapping w.
+//
+// key must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256.
+// If chunkSize is not positive, use DefaultChunkSize instead.
+// If flushInterval is positive, flush the data within flushInterval
+// after buffering it.
+func NewEncryptWriter(w io.Writer, key []byte, chunkSize int,
+	flushInterval time.Duration) (*EncryptWriter, error) {
+	if w == nil {
+		panic("NewEncryptWriter: io.Writer is nil")
+	}
+
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	ew := &EncryptWriter{writer: w, size: chunkSize, interval: flushInterval}
+	if _, err := rand.Read(ew.run[:]); err != nil {
+		return nil, err
+	}
+
+	aead, err := newRunGCM(key, ew.run[:])
+	if err != nil {
+		return nil, err
+	}
+
+	ew.aead = aead
+	ew.nonce = make([]byte, aead.NonceSize())
+	ew.data = make([]byte, plainLenSize, plainLenSize+chunkSize)
+	return ew, nil
+}
+
+// Unwrap returns the wrapped writer.
+func (w *EncryptWriter) Unwrap() io.Writer { return w.writer }
+
+// Write implements the interface io.Writer, which buffers p and writes
+// the full chunks into the wrapped writer.
+//
+// If failing to write a chunk, it is kept to write again later, and n is
+// the number of the bytes buffered, which will be written eventually.
+func (w *EncryptWriter) Write(p []byte) (n int, err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for len(p) > 0 {
+		if w.buffered() == w.size {
+			if err = w.writeChunk(); err != nil {
+				return
+			}
+		}
+
+		m := min(len(p), w.size-w.buffered())
+		w.data = append(w.data, p[:m]...)
+		p = p[m:]
+		n += m
+	}
+
+	if w.buffered() == w.size {
+		// The error is returned by the next Write or Flush,
+		// since the data has been accepted.
+		_ = w.writeChunk()
+	} else if w.buffered() > 0 && w.interval > 0 && w.timer == nil {
+		w.timer = time.AfterFunc(w.interval, w.flushByTimer)
+	}
+	return
+}
+
+func (w *EncryptWriter) buffered() int { return len(w.data) - plainLenSize }
+
+func (w *EncryptWriter) flushByTimer() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.timer = nil
+	_ = w.flush() // Kept to write again by the next Write or Flush.
+}
+
+// Flush pads and encrypts the buffered data into a chunk,
+// and writes it into the wrapped writer.
+func (w *EncryptWriter) Flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.flush()
+}
+
+func (w *EncryptWriter) flush() (err error) {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+
+	if w.buffered() > 0 {
+		err = w.writeChunk()
+	}
+	return
+}
+
+func (w *EncryptWriter) writeChunk() (err error) {
+	datalen := w.buffered()
+	plain := w.data[:plainLenSize+w.size]
+	binary.BigEndian.PutUint32(plain, uint32(datalen))
+	clear(plain[plainLenSize+datalen:])
+
+	sealedlen := len(plain) + w.aead.Overhead()
+	if _cap := chunkHeadSize + sealedlen; cap(w.buf) < _cap {
+		w.buf = make([]byte, chunkHeadSize, _cap)
+	}
+
+	w.buf = w.buf[:chunkHeadSize]
+	binary.BigEndian.PutUint32(w.buf, uint32(sealedlen))
+	copy(w.buf[chunkLenSize:], w.run[:])
+	binary.BigEndian.PutUint64(w.buf[chunkLenSize+chunkRunSize:], w.seq)
+
+	aad := w.buf[chunkLenSize:chunkHeadSize]
+	nonce := chunkNonce(w.nonce, aad[chunkRunSize:])
+	w.buf = w.aead.Seal(w.buf, nonce, plain, aad)
+	if _, err = w.writer.Write(w.buf); err == nil {
+		w.data = w.data[:plainLenSize]
+		w.seq++
+	}
+	return
+}
+
+// Close flushes the buffered data and closes the wrapped writer
+// if it implements io.Closer.
+func (w *EncryptWriter) Close() (err error) {
+	if err = w.Flush(); err != nil {
+		return
+	}
+
+	if c, ok := w.writer.(io.Closer); ok {
+		err = c.Close()
+	}
+	return
+}
+
+// Decrypt decrypts the data from src, which is written by EncryptWriter,
+// and writes the plaintext into dst.
+//
+// The sequences of the chunks in each run must be consecutive, and a new run
+// must start from the sequence 0. But the first chunk in src is not required
+// to be so, so a single rotated file can be decrypted.
+func Decrypt(dst io.Writer, src io.Reader, key []byte) (err error) {
+	if err = checkKey(key); err != nil {
+		return
+	}
+
+	var aead cipher.AEAD
+	var head, last [chunkHeadSize]byte
+	var buf, plain, nonce []byte
+	for index := 0; ; index++ {
+		if _, err = io.ReadFull(src, head[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("%w: chunk %d is truncated", ErrInvalidChunk, index)
+		}
+
+		aad := head[chunkLenSize:]
+		if index > 0 && !isNextChunk(last[chunkLenSize:], aad) {
+			return fmt.Errorf("%w: chunk %d is out of sequence", ErrInvalidChunk, index)
+		}
+
+		// Derive the subkey for each run.
+		if index == 0 || !bytes.Equal(last[chunkLenSize:chunkLenSize+chunkRunSize], aad[:chunkRunSize]) {
+			if aead, err = newRunGCM(key, aad[:chunkRunSize]); err != nil {
+				return
+			}
+			nonce = make([]byte, aead.NonceSize())
+		}
+
+		sealedlen := int(binary.BigEndian.Uint32(head[:]))
+		if sealedlen < aead.Overhead()+plainLenSize {
+			return fmt.Errorf("%w: chunk %d has the invalid length", ErrInvalidChunk, index)
+		}
+
+		if cap(buf) < sealedlen {
+			buf = make([]byte, sealedlen)
+		}
+		buf = buf[:sealedlen]
+		if _, err = io.ReadFull(src, buf); err != nil {
+			return fmt.Errorf("%w: chunk %d is truncated", ErrInvalidChunk, index)
+		}
+
+		plain, err = aead.Open(plain[:0], chunkNonce(nonce, aad[chunkRunSize:]), buf, aad)
+		if err != nil {
+			return fmt.Errorf("%w: chunk %d fails to be authenticated", ErrInvalidChunk, index)
+		}
+
+		datalen := int(binary.BigEndian.Uint32(plain))
+		if datalen > len(plain)-plainLenSize {
+			return fmt.Errorf("%w: chunk %d has the invalid data length", ErrInvalidChunk, index)
+		}
+
+		if _, err = dst.Write(plain[plainLenSize : plainLenSize+datalen]); err != nil {
+			return
+		}
+		last = head
+	}
+}
+
+// isNextChunk reports whether the chunk with the additional data next
+// follows the chunk with the additional data prev, that's, the next
+// sequence in the same run, or the first sequence in a new run.
+func isNextChunk(prev, next []byte) bool {
+	seq := binary.BigEndian.Uint64(next[chunkRunSize:])
+	if bytes.Equal(prev[:chunkRunSize], next[:chunkRunSize]) {
+		return seq == binary.BigEndian.Uint64(prev[chunkRunSize:])+1
+	}
+	return seq == 0
+}
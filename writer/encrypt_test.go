@@ -0,0 +1,179 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEncryptWriter(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	buf := new(bytes.Buffer)
+	w, err := NewEncryptWriter(buf, key, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write the full chunks only, and buffer the rest.
+	if _, err := w.Write([]byte("abcdefghij")); err != nil {
+		t.Fatal(err)
+	}
+	offset := buf.Len()
+	if _, err := w.Write([]byte("klmn")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// All the chunks have the same size.
+	chunklen := offset / 2
+	if len(data) != chunklen*4 {
+		t.Fatalf("expect %d chunks with %d bytes, but got %d bytes", 4, chunklen, len(data))
+	}
+
+	plain := new(bytes.Buffer)
+	if err := Decrypt(plain, bytes.NewReader(data), key); err != nil {
+		t.Fatal(err)
+	} else if s := plain.String(); s != "abcdefghijklmn" {
+		t.Errorf("expect '%s', but got '%s'", "abcdefghijklmn", s)
+	}
+
+	// Decrypt the rest chunks independently.
+	plain.Reset()
+	if err := Decrypt(plain, bytes.NewReader(data[offset:]), key); err != nil {
+		t.Fatal(err)
+	} else if s := plain.String(); s != "ijklmn" {
+		t.Errorf("expect '%s', but got '%s'", "ijklmn", s)
+	}
+
+	modified := bytes.Clone(data)
+	modified[len(modified)-1] ^= 0xff
+	if err := Decrypt(new(bytes.Buffer), bytes.NewReader(modified), key); !errors.Is(err, ErrInvalidChunk) {
+		t.Errorf("expect ErrInvalidChunk for the modification, but got %v", err)
+	}
+
+	if err := Decrypt(new(bytes.Buffer), bytes.NewReader(data[:len(data)-1]), key); !errors.Is(err, ErrInvalidChunk) {
+		t.Errorf("expect ErrInvalidChunk for the truncation, but got %v", err)
+	}
+
+	removed := append(bytes.Clone(data[:chunklen]), data[2*chunklen:]...)
+	if err := Decrypt(new(bytes.Buffer), bytes.NewReader(removed), key); !errors.Is(err, ErrInvalidChunk) {
+		t.Errorf("expect ErrInvalidChunk for the removal, but got %v", err)
+	}
+
+	if err := Decrypt(new(bytes.Buffer), bytes.NewReader(data), key[:16]); !errors.Is(err, ErrInvalidChunk) {
+		t.Errorf("expect ErrInvalidChunk for another key, but got %v", err)
+	}
+}
+
+func TestEncryptWriterAppend(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	buf := new(bytes.Buffer)
+
+	// Simulate that the process is restarted to append the same file.
+	for _, s := range []string{"abcdef", "ghijkl"} {
+		w, err := NewEncryptWriter(buf, key, 4, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := buf.Bytes()
+
+	plain := new(bytes.Buffer)
+	if err := Decrypt(plain, bytes.NewReader(data), key); err != nil {
+		t.Fatal(err)
+	} else if s := plain.String(); s != "abcdefghijkl" {
+		t.Errorf("expect '%s', but got '%s'", "abcdefghijkl", s)
+	}
+
+	// Remove the first chunk of the second run, which has 2 chunks.
+	chunklen := len(data) / 4
+	start := chunklen * 2
+	removed := append(bytes.Clone(data[:start]), data[start+chunklen:]...)
+	if err := Decrypt(new(bytes.Buffer), bytes.NewReader(removed), key); !errors.Is(err, ErrInvalidChunk) {
+		t.Errorf("expect ErrInvalidChunk for the removal at the run start, but got %v", err)
+	}
+}
+
+func TestEncryptWriterFlushInterval(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	buf := new(bytes.Buffer)
+	w, err := NewEncryptWriter(buf, key, 1024, time.Millisecond*10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The chunk is written by the timer with the lock.
+	written := func() bool { w.lock.Lock(); defer w.lock.Unlock(); return buf.Len() > 0 }
+	for deadline := time.Now().Add(time.Second * 5); !written() && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	plain := new(bytes.Buffer)
+	if err := Decrypt(plain, bytes.NewReader(buf.Bytes()), key); err != nil {
+		t.Fatal(err)
+	} else if s := plain.String(); s != "abc" {
+		t.Errorf("expect '%s', but got '%s'", "abc", s)
+	}
+}
+
+func TestEncryptWriterRetry(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	buf := &toggleWriter{fail: true}
+	w, err := NewEncryptWriter(buf, key, 4, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The data is accepted, though the full chunk fails to be written.
+	if n, err := w.Write([]byte("abcd")); n != 4 || err != nil {
+		t.Errorf("expect to buffer %d bytes, but got %d, %v", 4, n, err)
+	}
+
+	// Nothing is accepted since the buffer is full.
+	if n, err := w.Write([]byte("efgh")); n != 0 || err == nil {
+		t.Errorf("expect to fail to write without any data, but got %d, %v", n, err)
+	}
+
+	buf.fail = false
+	if _, err := w.Write([]byte("efgh")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	plain := new(bytes.Buffer)
+	if err := Decrypt(plain, bytes.NewReader(buf.Bytes()), key); err != nil {
+		t.Fatal(err)
+	} else if s := plain.String(); s != "abcdefgh" {
+		t.Errorf("expect '%s', but got '%s'", "abcdefgh", s)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Failover is a writer proxy, which writes the data into the primary writer,
+// and fails over to the secondary writer, such as os.Stderr, after
+// the primary fails to write consecutively.
+//
+// When failing over, the primary is probed periodically by writing the data
+// into it instead of the secondary, and switched back if it is healthy.
+type Failover struct {
+	// Threshold is the number of the consecutive failures of the primary
+	// to fail over to the secondary.
+	//
+	// Default: 3
+	Threshold int
+
+	// ProbeInterval is the interval to probe the primary when failing over.
+	//
+	// Default: 10s
+	ProbeInterval time.Duration
+
+	// OnSwitch is called after switching the writer, which is called
+	// with true and the primary error when failing over to the secondary,
+	// or with false and nil when switching back to the primary.
+	//
+	// Default: nil
+	OnSwitch func(failover bool, err error)
+
+	primary   io.Writer
+	secondary io.Writer
+
+	lock      sync.Mutex
+	failures  int
+	failover  bool
+	lastprobe time.Time
+}
+
+// NewFailover returns a new Failover writer.
+func NewFailover(primary, secondary io.Writer) *Failover {
+	if primary == nil {
+		panic("NewFailover: the primary io.Writer is nil")
+	}
+	if secondary == nil {
+		panic("NewFailover: the secondary io.Writer is nil")
+	}
+	return &Failover{primary: primary, secondary: secondary}
+}
+
+// Primary returns the primary writer.
+func (f *Failover) Primary() io.Writer { return f.primary }
+
+// Secondary returns the secondary writer.
+func (f *Failover) Secondary() io.Writer { return f.secondary }
+
+// IsFailover reports whether it has failed over to the secondary.
+func (f *Failover) IsFailover() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.failover
+}
+
+// Write implements the interface io.Writer.
+//
+// If the primary fails to write, the data will be written into the secondary.
+func (f *Failover) Write(p []byte) (n int, err error) {
+	var switched bool
+	var primaryErr error
+
+	f.lock.Lock()
+	if f.failover {
+		n, switched, err = f.probe(p)
+	} else {
+		n, switched, err = f.write(p)
+		primaryErr = err
+		if err != nil {
+			n, err = f.secondary.Write(p)
+		}
+	}
+	f.lock.Unlock()
+
+	if switched && f.OnSwitch != nil {
+		f.OnSwitch(primaryErr != nil, primaryErr)
+	}
+	return
+}
+
+func (f *Failover) write(p []byte) (n int, switched bool, err error) {
+	if n, err = f.primary.Write(p); err == nil {
+		f.failures = 0
+		return
+	}
+
+	threshold := f.Threshold
+	if threshold <= 0 {
+		threshold = 3
+	}
+
+	if f.failures++; f.failures >= threshold {
+		f.failures = 0
+		f.failover = true
+		f.lastprobe = time.Now()
+		switched = true
+	}
+	return
+}
+
+func (f *Failover) probe(p []byte) (n int, switched bool, err error) {
+	interval := f.ProbeInterval
+	if interval <= 0 {
+		interval = time.Second * 10
+	}
+
+	if now := time.Now(); now.Sub(f.lastprobe) >= interval {
+		f.lastprobe = now
+		if n, err = f.primary.Write(p); err == nil {
+			f.failover = false
+			switched = true
+			return
+		}
+	}
+
+	n, err = f.secondary.Write(p)
+	return
+}
+
+// Close implements the interface io.Closer, which closes the primary
+// if it implements io.Closer. The secondary is not closed.
+func (f *Failover) Close() error {
+	if c, ok := f.primary.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+type toggleWriter struct {
+	bytes.Buffer
+	fail bool
+}
+
+func (w *toggleWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, errors.New("write error")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestFailover(t *testing.T) {
+	var switches []bool
+	primary, secondary := new(toggleWriter), new(bytes.Buffer)
+	w := NewFailover(primary, secondary)
+	w.Threshold = 2
+	w.ProbeInterval = time.Millisecond * 10
+	w.OnSwitch = func(failover bool, err error) { switches = append(switches, failover) }
+
+	w.Write([]byte("a"))
+	primary.fail = true
+	w.Write([]byte("b"))
+	if w.IsFailover() {
+		t.Errorf("unexpected failover")
+	}
+	w.Write([]byte("c"))
+	if !w.IsFailover() {
+		t.Errorf("expect failover, but not")
+	}
+	w.Write([]byte("d"))
+
+	primary.fail = false
+	w.Write([]byte("e"))
+	time.Sleep(w.ProbeInterval)
+	w.Write([]byte("f"))
+	if w.IsFailover() {
+		t.Errorf("expect switching back, but not")
+	}
+	w.Write([]byte("g"))
+
+	if s := primary.String(); s != "afg" {
+		t.Errorf("expect primary '%s', but got '%s'", "afg", s)
+	}
+	if s := secondary.String(); s != "bcde" {
+		t.Errorf("expect secondary '%s', but got '%s'", "bcde", s)
+	}
+	if len(switches) != 2 || !switches[0] || switches[1] {
+		t.Errorf("unexpected switches %v", switches)
+	}
+}
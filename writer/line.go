@@ -0,0 +1,139 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// DefaultTruncationMarker is the default marker appended to the truncated line.
+const DefaultTruncationMarker = "...(truncated)"
+
+// LineWriter is a writer proxy, which buffers the data until newline,
+// and writes each complete line into the wrapped writer by one Write call,
+// so the line is not interleaved with the data of the other writers
+// sharing the wrapped writer.
+//
+// If a line is longer than the maximum size, it is truncated and appended
+// with the truncation marker and a newline, and the rest is discarded.
+type LineWriter struct {
+	// TruncationMarker is appended to the truncated line.
+	//
+	// Default: DefaultTruncationMarker
+	TruncationMarker string
+
+	writer     io.Writer
+	maxsize    int
+	lock       sync.Mutex
+	buf        []byte
+	truncating bool
+}
+
+// NewLineWriter returns a new LineWriter wrapping w.
+//
+// maxLineSize is the maximum size of each line including the newline.
+// If it is not positive, use 64KB instead.
+func NewLineWriter(w io.Writer, maxLineSize int) *LineWriter {
+	if w == nil {
+		panic("NewLineWriter: io.Writer is nil")
+	}
+	if maxLineSize <= 0 {
+		maxLineSize = 64 * 1024
+	}
+	return &LineWriter{writer: w, maxsize: maxLineSize, TruncationMarker: DefaultTruncationMarker}
+}
+
+// Unwrap returns the wrapped writer.
+func (w *LineWriter) Unwrap() io.Writer { return w.writer }
+
+// Write implements the interface io.Writer.
+func (w *LineWriter) Write(p []byte) (n int, err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for len(p) > 0 {
+		line := p
+		index := bytes.IndexByte(p, '\n')
+		if index > -1 {
+			line = p[:index+1]
+		}
+
+		if err = w.writeLine(line, index > -1); err != nil {
+			return
+		}
+
+		n += len(line)
+		p = p[len(line):]
+	}
+
+	return
+}
+
+func (w *LineWriter) writeLine(line []byte, complete bool) (err error) {
+	switch {
+	case w.truncating:
+		// Discard the rest of the truncated line.
+		w.truncating = !complete
+
+	case len(w.buf)+len(line) > w.maxsize:
+		w.buf = append(w.buf, line[:w.maxsize-len(w.buf)]...)
+		w.buf = append(w.buf, w.TruncationMarker...)
+		w.buf = append(w.buf, '\n')
+		w.truncating = !complete
+		err = w.emit()
+
+	case !complete:
+		w.buf = append(w.buf, line...)
+
+	case len(w.buf) == 0:
+		_, err = w.writer.Write(line)
+
+	default:
+		w.buf = append(w.buf, line...)
+		err = w.emit()
+	}
+	return
+}
+
+func (w *LineWriter) emit() (err error) {
+	_, err = w.writer.Write(w.buf)
+	w.buf = w.buf[:0]
+	return
+}
+
+// Flush writes the buffered incomplete line into the wrapped writer.
+func (w *LineWriter) Flush() (err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.buf) > 0 {
+		err = w.emit()
+	}
+	return
+}
+
+// Close flushes the buffered data and closes the wrapped writer
+// if it implements io.Closer.
+func (w *LineWriter) Close() (err error) {
+	if err = w.Flush(); err != nil {
+		return
+	}
+
+	if c, ok := w.writer.(io.Closer); ok {
+		err = c.Close()
+	}
+	return
+}
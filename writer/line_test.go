@@ -0,0 +1,49 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"slices"
+	"testing"
+)
+
+type recordWriter struct{ writes []string }
+
+func (w *recordWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestLineWriter(t *testing.T) {
+	rw := new(recordWriter)
+	w := NewLineWriter(rw, 8)
+	w.TruncationMarker = "..."
+
+	for _, s := range []string{"ab", "c\nde", "f\n", "0123456789", "abc\nxyz"} {
+		if n, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		} else if n != len(s) {
+			t.Errorf("expect %d bytes, but got %d", len(s), n)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expects := []string{"abc\n", "def\n", "01234567...\n", "xyz"}
+	if !slices.Equal(expects, rw.writes) {
+		t.Errorf("expect %q, but got %q", expects, rw.writes)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrAllTargetsFailed is returned by Multi.Write when no target is written.
+var ErrAllTargetsFailed = errors.New("all the targets failed to write")
+
+type target struct {
+	name   string
+	writer io.Writer
+
+	failures   atomic.Int64 // The number of the consecutive failures.
+	quarantine atomic.Int64 // The unix nano time until which it is quarantined.
+}
+
+// Multi is a tee writer proxy, which writes the data into all the targets.
+//
+// Unlike io.MultiWriter, a failed target does not fail the whole Write
+// and hinder the other targets, which is only reported by OnError and
+// optionally quarantined for a while.
+type Multi struct {
+	// OnError is called when a target fails to write. Default: nil
+	OnError func(name string, w io.Writer, err error)
+
+	// If QuarantineThreshold is positive and a target fails consecutively
+	// for QuarantineThreshold times, it will be skipped in QuarantineDuration.
+	//
+	// Default: 0, QuarantineDuration is 10s.
+	QuarantineThreshold int
+	QuarantineDuration  time.Duration
+
+	lock    sync.Mutex   // Only for add and remove
+	targets atomic.Value // []*target
+}
+
+// NewMulti returns a new Multi writer.
+func NewMulti() *Multi {
+	m := new(Multi)
+	m.targets.Store([]*target(nil))
+	return m
+}
+
+func (m *Multi) loadTargets() []*target {
+	targets, _ := m.targets.Load().([]*target)
+	return targets
+}
+
+// Add adds the target writer with the unique name, which replaces
+// and returns the old if the name has existed.
+func (m *Multi) Add(name string, w io.Writer) (old io.Writer) {
+	if w == nil {
+		panic("Multi.Add: io.Writer is nil")
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	targets := m.loadTargets()
+	newtargets := make([]*target, 0, len(targets)+1)
+	for _, t := range targets {
+		if t.name == name {
+			old = t.writer
+		} else {
+			newtargets = append(newtargets, t)
+		}
+	}
+
+	m.targets.Store(append(newtargets, &target{name: name, writer: w}))
+	return
+}
+
+// Remove removes and returns the target writer by the name.
+//
+// Return nil if the target does not exist.
+func (m *Multi) Remove(name string) (old io.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	targets := m.loadTargets()
+	newtargets := make([]*target, 0, len(targets))
+	for _, t := range targets {
+		if t.name == name {
+			old = t.writer
+		} else {
+			newtargets = append(newtargets, t)
+		}
+	}
+
+	if old != nil {
+		m.targets.Store(newtargets)
+	}
+	return
+}
+
+// Targets returns the names of all the targets.
+func (m *Multi) Targets() []string {
+	targets := m.loadTargets()
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = t.name
+	}
+	return names
+}
+
+// Quarantined reports whether the target named name is being quarantined.
+func (m *Multi) Quarantined(name string) bool {
+	now := time.Now().UnixNano()
+	for _, t := range m.loadTargets() {
+		if t.name == name {
+			return t.quarantine.Load() > now
+		}
+	}
+	return false
+}
+
+// Write implements the interface io.Writer, which writes the data into
+// all the targets that are not quarantined.
+//
+// It returns ErrAllTargetsFailed only if all the tried targets fail.
+func (m *Multi) Write(p []byte) (n int, err error) {
+	var tried, failed int
+	now := time.Now()
+	for _, t := range m.loadTargets() {
+		if t.quarantine.Load() > now.UnixNano() {
+			continue
+		}
+
+		tried++
+		if _, err := t.writer.Write(p); err != nil {
+			failed++
+			m.fail(t, now, err)
+		} else {
+			t.failures.Store(0)
+		}
+	}
+
+	if tried > 0 && tried == failed {
+		return 0, ErrAllTargetsFailed
+	}
+	return len(p), nil
+}
+
+func (m *Multi) fail(t *target, now time.Time, err error) {
+	failures := t.failures.Add(1)
+	if m.OnError != nil {
+		m.OnError(t.name, t.writer, err)
+	}
+
+	if m.QuarantineThreshold > 0 && failures >= int64(m.QuarantineThreshold) {
+		duration := m.QuarantineDuration
+		if duration <= 0 {
+			duration = time.Second * 10
+		}
+
+		t.failures.Store(0)
+		t.quarantine.Store(now.Add(duration).UnixNano())
+	}
+}
+
+// Close implements the interface io.Closer, which closes all the targets
+// implementing io.Closer and returns the first error.
+func (m *Multi) Close() (err error) {
+	for _, t := range m.loadTargets() {
+		if c, ok := t.writer.(io.Closer); ok {
+			if _err := c.Close(); err == nil {
+				err = _err
+			}
+		}
+	}
+	return
+}
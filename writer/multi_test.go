@@ -0,0 +1,72 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type errWriter struct{ n int }
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	w.n++
+	return 0, errors.New("write error")
+}
+
+func TestMulti(t *testing.T) {
+	var errs int
+	m := NewMulti()
+	m.QuarantineThreshold = 2
+	m.OnError = func(string, io.Writer, error) { errs++ }
+
+	buf1, buf2, bad := new(bytes.Buffer), new(bytes.Buffer), new(errWriter)
+	m.Add("buf1", buf1)
+	m.Add("buf2", buf2)
+	m.Add("bad", bad)
+
+	for i := 0; i < 3; i++ {
+		if n, err := m.Write([]byte("abc")); err != nil {
+			t.Fatal(err)
+		} else if n != 3 {
+			t.Errorf("expect %d bytes, but got %d", 3, n)
+		}
+	}
+
+	if s := buf1.String(); s != "abcabcabc" {
+		t.Errorf("expect '%s', but got '%s'", "abcabcabc", s)
+	}
+	if s := buf2.String(); s != "abcabcabc" {
+		t.Errorf("expect '%s', but got '%s'", "abcabcabc", s)
+	}
+	if errs != 2 || bad.n != 2 {
+		t.Errorf("expect %d errors, but got %d", 2, errs)
+	}
+	if !m.Quarantined("bad") {
+		t.Errorf("expect the target quarantined, but not")
+	}
+
+	if w := m.Remove("buf1"); w != buf1 {
+		t.Errorf("expect removing buf1, but got %v", w)
+	}
+	m.Remove("buf2")
+	m.Remove("bad")
+	m.Add("bad", bad)
+	if _, err := m.Write([]byte("abc")); !errors.Is(err, ErrAllTargetsFailed) {
+		t.Errorf("expect ErrAllTargetsFailed, but got %v", err)
+	}
+}
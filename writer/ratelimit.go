@@ -0,0 +1,136 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LimitPolicy is the policy when the writes are over the budget.
+type LimitPolicy int
+
+// Predefine some limit policies.
+const (
+	LimitBlock     LimitPolicy = iota // Block until the budget is available.
+	LimitDrop                         // Drop the data silently.
+	LimitDropCount                    // Drop the data and count it.
+)
+
+// LimitWriter is a writer proxy, which caps the bandwidth of the writes
+// by a token bucket.
+//
+// When dropping the data over the budget, Write does not return an error,
+// so the caller, such as slog, is not hindered.
+type LimitWriter struct {
+	writer io.Writer
+	policy LimitPolicy
+	rate   float64 // bytes per second
+	burst  float64
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+
+	dropWrites atomic.Uint64
+	dropBytes  atomic.Uint64
+}
+
+// NewLimitWriter returns a new LimitWriter wrapping w, which allows
+// bytesPerSecond bytes per second with the burst of burst bytes.
+//
+// If burst is less than bytesPerSecond, use bytesPerSecond instead.
+func NewLimitWriter(w io.Writer, bytesPerSecond, burst int, policy LimitPolicy) *LimitWriter {
+	if w == nil {
+		panic("NewLimitWriter: io.Writer is nil")
+	}
+	if bytesPerSecond <= 0 {
+		panic("NewLimitWriter: bytesPerSecond must be positive")
+	}
+	if burst < bytesPerSecond {
+		burst = bytesPerSecond
+	}
+
+	return &LimitWriter{
+		writer: w,
+		policy: policy,
+		rate:   float64(bytesPerSecond),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Unwrap returns the wrapped writer.
+func (w *LimitWriter) Unwrap() io.Writer { return w.writer }
+
+// Dropped returns the number and bytes of the dropped writes,
+// which is only counted by the policy LimitDropCount.
+func (w *LimitWriter) Dropped() (writes, bytes uint64) {
+	return w.dropWrites.Load(), w.dropBytes.Load()
+}
+
+// Write implements the interface io.Writer.
+func (w *LimitWriter) Write(p []byte) (n int, err error) {
+	wait, ok := w.reserve(len(p))
+	if !ok {
+		if w.policy == LimitDropCount {
+			w.dropWrites.Add(1)
+			w.dropBytes.Add(uint64(len(p)))
+		}
+		return len(p), nil
+	}
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+	return w.writer.Write(p)
+}
+
+// reserve takes n tokens from the bucket, and returns the duration
+// to wait for them, or false if dropping the data.
+func (w *LimitWriter) reserve(n int) (wait time.Duration, ok bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	now := time.Now()
+	w.tokens += now.Sub(w.last).Seconds() * w.rate
+	if w.tokens > w.burst {
+		w.tokens = w.burst
+	}
+	w.last = now
+
+	// Allow the data larger than the burst when the bucket is full.
+	need := min(float64(n), w.burst)
+	if w.tokens < need && w.policy != LimitBlock {
+		return 0, false
+	}
+
+	if w.tokens < need {
+		wait = time.Duration((need - w.tokens) / w.rate * float64(time.Second))
+	}
+	w.tokens -= float64(n)
+	return wait, true
+}
+
+// Close closes the wrapped writer if it implements io.Closer.
+func (w *LimitWriter) Close() error {
+	if c, ok := w.writer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
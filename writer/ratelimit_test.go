@@ -0,0 +1,111 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLimitWriterDrop(t *testing.T) {
+	for _, policy := range []LimitPolicy{LimitDrop, LimitDropCount} {
+		buf := new(bytes.Buffer)
+		w := NewLimitWriter(buf, 100, 100, policy)
+
+		if n, err := w.Write(make([]byte, 100)); err != nil || n != 100 {
+			t.Fatalf("expect to write %d bytes, but got %d, %v", 100, n, err)
+		}
+
+		// The bucket is empty, so drop the data silently.
+		if n, err := w.Write(make([]byte, 10)); err != nil || n != 10 {
+			t.Errorf("expect to drop %d bytes silently, but got %d, %v", 10, n, err)
+		}
+		if buf.Len() != 100 {
+			t.Errorf("expect %d bytes written, but got %d", 100, buf.Len())
+		}
+
+		writes, bytes := w.Dropped()
+		switch policy {
+		case LimitDrop:
+			if writes != 0 || bytes != 0 {
+				t.Errorf("expect no dropped counts, but got %d writes and %d bytes", writes, bytes)
+			}
+		case LimitDropCount:
+			if writes != 1 || bytes != 10 {
+				t.Errorf("expect 1 dropped write with 10 bytes, but got %d writes and %d bytes", writes, bytes)
+			}
+		}
+	}
+}
+
+func TestLimitWriterBlock(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewLimitWriter(buf, 100, 100, LimitBlock)
+
+	// Use the long wait to leave the wide margin for the slow scheduling.
+	start := time.Now()
+	w.Write(make([]byte, 100))
+	if elapsed := time.Since(start); elapsed > time.Millisecond*250 {
+		t.Errorf("expect the burst not to block, but blocked %s", elapsed)
+	}
+
+	// Wait for 50 tokens at 100 bytes per second.
+	w.Write(make([]byte, 50))
+	if elapsed := time.Since(start); elapsed < time.Millisecond*450 {
+		t.Errorf("expect to block about 500ms, but blocked %s", elapsed)
+	}
+
+	if buf.Len() != 150 {
+		t.Errorf("expect %d bytes written, but got %d", 150, buf.Len())
+	}
+	if writes, bytes := w.Dropped(); writes != 0 || bytes != 0 {
+		t.Errorf("expect nothing dropped, but got %d writes and %d bytes", writes, bytes)
+	}
+}
+
+func TestLimitWriterOverBurst(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewLimitWriter(buf, 100, 100, LimitDropCount)
+
+	// The data larger than the burst is allowed when the bucket is full,
+	// but it overdraws the tokens.
+	if n, err := w.Write(make([]byte, 300)); err != nil || n != 300 {
+		t.Fatalf("expect to write %d bytes, but got %d, %v", 300, n, err)
+	}
+	if buf.Len() != 300 {
+		t.Errorf("expect %d bytes written, but got %d", 300, buf.Len())
+	}
+
+	// The overdrawn tokens are not restored in 100ms.
+	time.Sleep(time.Millisecond * 100)
+	w.Write(make([]byte, 1))
+	if writes, bytes := w.Dropped(); writes != 1 || bytes != 1 {
+		t.Errorf("expect 1 dropped write with 1 byte, but got %d writes and %d bytes", writes, bytes)
+	}
+
+	// The data larger than the burst is dropped when the bucket is not full.
+	w = NewLimitWriter(new(bytes.Buffer), 100, 100, LimitDropCount)
+	w.Write(make([]byte, 1))
+	w.Write(make([]byte, 300))
+	if writes, bytes := w.Dropped(); writes != 1 || bytes != 300 {
+		t.Errorf("expect 1 dropped write with 300 bytes, but got %d writes and %d bytes", writes, bytes)
+	}
+
+	// The burst is at least the rate.
+	if w = NewLimitWriter(new(bytes.Buffer), 100, 10, LimitDrop); w.burst != 100 {
+		t.Errorf("expect the burst %d, but got %v", 100, w.burst)
+	}
+}
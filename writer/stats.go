@@ -0,0 +1,117 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyWindow is the number of the latest writes to compute the latency.
+const latencyWindow = 1024
+
+// Stats is the statistics of the writes.
+type Stats struct {
+	Bytes     uint64 `json:"bytes"`
+	Writes    uint64 `json:"writes"`
+	Errors    uint64 `json:"errors"`
+	LastError string `json:"last_error,omitempty"`
+
+	// P99Latency is the p99 latency of the latest 1024 writes.
+	P99Latency time.Duration `json:"p99_latency"`
+}
+
+// StatsWriter is a writer proxy, which collects the statistics of the writes.
+type StatsWriter struct {
+	writer  io.Writer
+	bytes   atomic.Uint64
+	writes  atomic.Uint64
+	errors  atomic.Uint64
+	lasterr atomic.Pointer[string]
+
+	lock      sync.Mutex
+	latencies [latencyWindow]time.Duration
+	index     int
+	count     int
+}
+
+// NewStatsWriter returns a new StatsWriter wrapping w.
+func NewStatsWriter(w io.Writer) *StatsWriter {
+	if w == nil {
+		panic("NewStatsWriter: io.Writer is nil")
+	}
+	return &StatsWriter{writer: w}
+}
+
+// Unwrap returns the wrapped writer.
+func (w *StatsWriter) Unwrap() io.Writer { return w.writer }
+
+// Write implements the interface io.Writer.
+func (w *StatsWriter) Write(p []byte) (n int, err error) {
+	start := time.Now()
+	n, err = w.writer.Write(p)
+	latency := time.Since(start)
+
+	w.writes.Add(1)
+	w.bytes.Add(uint64(n))
+	if err != nil {
+		w.errors.Add(1)
+		errmsg := err.Error()
+		w.lasterr.Store(&errmsg)
+	}
+
+	w.lock.Lock()
+	w.latencies[w.index] = latency
+	w.index = (w.index + 1) % latencyWindow
+	if w.count < latencyWindow {
+		w.count++
+	}
+	w.lock.Unlock()
+
+	return
+}
+
+// Close implements the interface io.Closer, which closes the wrapped writer
+// if it implements io.Closer.
+func (w *StatsWriter) Close() error {
+	if c, ok := w.writer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Stats returns the statistics of the writes.
+func (w *StatsWriter) Stats() (stats Stats) {
+	stats.Bytes = w.bytes.Load()
+	stats.Writes = w.writes.Load()
+	stats.Errors = w.errors.Load()
+	if errmsg := w.lasterr.Load(); errmsg != nil {
+		stats.LastError = *errmsg
+	}
+
+	w.lock.Lock()
+	latencies := slices.Clone(w.latencies[:w.count])
+	w.lock.Unlock()
+
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		stats.P99Latency = latencies[(len(latencies)*99-1)/100]
+	}
+
+	return
+}
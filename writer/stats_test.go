@@ -0,0 +1,87 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsWriter(t *testing.T) {
+	tw := new(toggleWriter)
+	w := NewStatsWriter(tw)
+
+	w.Write([]byte("abc"))
+	w.Write([]byte("de"))
+	tw.fail = true
+	if _, err := w.Write([]byte("fgh")); err == nil {
+		t.Error("expect an error, but got nil")
+	}
+
+	stats := w.Stats()
+	if stats.Bytes != 5 {
+		t.Errorf("expect %d bytes, but got %d", 5, stats.Bytes)
+	}
+	if stats.Writes != 3 {
+		t.Errorf("expect %d writes, but got %d", 3, stats.Writes)
+	}
+	if stats.Errors != 1 {
+		t.Errorf("expect %d errors, but got %d", 1, stats.Errors)
+	}
+	if stats.LastError != "write error" {
+		t.Errorf("expect the last error '%s', but got '%s'", "write error", stats.LastError)
+	}
+}
+
+func TestStatsWriterP99Latency(t *testing.T) {
+	w := NewStatsWriter(new(toggleWriter))
+	if p99 := w.Stats().P99Latency; p99 != 0 {
+		t.Errorf("expect no latency, but got %s", p99)
+	}
+
+	// 100 latencies: the p99 is the 99th smallest, that's, index 98.
+	for i := 1; i <= 100; i++ {
+		w.latencies[i-1] = time.Duration(i)
+	}
+	w.index, w.count = 100, 100
+	if p99 := w.Stats().P99Latency; p99 != 99 {
+		t.Errorf("expect p99 %d, but got %d", 99, p99)
+	}
+
+	// 1 latency: the p99 is itself.
+	w.latencies[0] = 7
+	w.index, w.count = 1, 1
+	if p99 := w.Stats().P99Latency; p99 != 7 {
+		t.Errorf("expect p99 %d, but got %d", 7, p99)
+	}
+
+	// Wrap around the window.
+	w.index, w.count = 0, 0
+	for i := 0; i < latencyWindow+10; i++ {
+		w.Write([]byte("a"))
+	}
+	if w.count != latencyWindow || w.index != 10 {
+		t.Errorf("expect count %d and index %d, but got %d and %d",
+			latencyWindow, 10, w.count, w.index)
+	}
+
+	// The full window: the p99 is at index (1024*99-1)/100 = 1013.
+	for i := range w.latencies {
+		w.latencies[i] = time.Duration(latencyWindow - i)
+	}
+	if p99 := w.Stats().P99Latency; p99 != 1014 {
+		t.Errorf("expect p99 %d, but got %d", 1014, p99)
+	}
+}
@@ -16,14 +16,40 @@ package writer
 
 import (
 	"io"
+	"sync"
 	"sync/atomic"
 )
 
-type wrapper struct{ io.Writer }
+type wrapper struct {
+	io.Writer
+
+	// lock is used to count the in-flight writes as the readers,
+	// so retiring the writer as the writer waits for all of them.
+	lock    sync.RWMutex
+	retired bool
+}
+
+func (w *wrapper) write(b []byte) (n int, ok bool, err error) {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+	if w.retired {
+		return
+	}
+
+	n, err = w.Writer.Write(b)
+	return n, true, err
+}
+
+func (w *wrapper) retire() io.Writer {
+	w.lock.Lock()
+	w.retired = true
+	w.lock.Unlock()
+	return w.Writer
+}
 
 // Switcher is a writer proxy, which can switch the writer to another
 // in running.
-type Switcher struct{ w atomic.Value }
+type Switcher struct{ w atomic.Pointer[wrapper] }
 
 // NewSwitcher returns a new Switcher with w.
 func NewSwitcher(writer io.Writer) *Switcher {
@@ -34,7 +60,12 @@ func NewSwitcher(writer io.Writer) *Switcher {
 
 // Write implements the interface io.Writer.
 func (w *Switcher) Write(b []byte) (int, error) {
-	return w.Get().Write(b)
+	for {
+		// If the loaded writer has been retired, reload the new one.
+		if n, ok, err := w.w.Load().write(b); ok {
+			return n, err
+		}
+	}
 }
 
 // Close implements the interface io.Closer.
@@ -50,23 +81,44 @@ func (w *Switcher) Set(new io.Writer) {
 	if new == nil {
 		panic("Switcher.Set: io.Writer is nil")
 	}
-	w.w.Store(wrapper{Writer: new})
+	w.w.Store(&wrapper{Writer: new})
 }
 
 // Get returns the wrapped writer, which is equal to Unwrap.
 func (w *Switcher) Get() io.Writer {
-	return w.w.Load().(wrapper).Writer
+	return w.w.Load().Writer
 }
 
 // Unwrap returns the wrapped writer.
 func (w *Switcher) Unwrap() io.Writer {
-	return w.w.Load().(wrapper).Writer
+	return w.w.Load().Writer
 }
 
 // Swap swaps the old writer with the new writer.
+//
+// Notice: the old writer may be still being written by the in-flight writes,
+// so use SwapAndWait or Retire instead if the old will be closed.
 func (w *Switcher) Swap(new io.Writer) (old io.Writer) {
 	if new == nil {
 		panic("Switcher.Swap: io.Writer is nil")
 	}
-	return w.w.Swap(wrapper{Writer: new}).(wrapper).Writer
+	return w.w.Swap(&wrapper{Writer: new}).Writer
+}
+
+// SwapAndWait is the same as Swap, but waits for all the in-flight writes
+// to the old writer to finish before returning, so it can be closed safely.
+func (w *Switcher) SwapAndWait(new io.Writer) (old io.Writer) {
+	if new == nil {
+		panic("Switcher.SwapAndWait: io.Writer is nil")
+	}
+	return w.w.Swap(&wrapper{Writer: new}).retire()
+}
+
+// Retire swaps the old writer with the new writer, and closes the old
+// if it implements io.Closer after all the in-flight writes to it finish.
+func (w *Switcher) Retire(new io.Writer) error {
+	if c, ok := w.SwapAndWait(new).(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
 }
@@ -0,0 +1,69 @@
+// Copyright 2026 xgfone
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type closeWriter struct {
+	closed atomic.Bool
+	writes atomic.Int64
+}
+
+func (w *closeWriter) Write(p []byte) (int, error) {
+	if w.closed.Load() {
+		return 0, errors.New("the writer has been closed")
+	}
+	w.writes.Add(1)
+	return len(p), nil
+}
+
+func (w *closeWriter) Close() error {
+	w.closed.Store(true)
+	return nil
+}
+
+func TestSwitcherRetire(t *testing.T) {
+	sw := NewSwitcher(new(closeWriter))
+
+	var wg sync.WaitGroup
+	var failures atomic.Int64
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if _, err := sw.Write([]byte("abc")); err != nil {
+					failures.Add(1)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		if err := sw.Retire(new(closeWriter)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+
+	if n := failures.Load(); n > 0 {
+		t.Errorf("expect no write failures, but got %d", n)
+	}
+}